@@ -0,0 +1,154 @@
+package curlreq
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client sends parsed requests with the transfer options of the command (Parsed.Transfer).
+type Client struct {
+	// Transport is the base transport that is cloned and configured for each request.
+	// If nil, http.DefaultTransport is used.
+	Transport *http.Transport
+}
+
+// Do sends the request of the command with ctx and returns the response.
+func (p *Parsed) Do(ctx context.Context) (*http.Response, error) {
+	return (&Client{}).Do(ctx, p)
+}
+
+// Do sends the request of p with ctx and returns the response.
+// Redirects are not followed unless -L/--location is specified, as curl does.
+func (c *Client) Do(ctx context.Context, p *Parsed) (*http.Response, error) {
+	req, err := p.Request()
+	if err != nil {
+		return nil, err
+	}
+	hc := c.HTTPClient(p)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		hc.CloseIdleConnections()
+		return nil, err
+	}
+	// The transport is only for this request
+	resp.Body = &idleClosingBody{ReadCloser: resp.Body, client: hc}
+	return resp, nil
+}
+
+// HTTPClient returns *http.Client configured with the transfer options of p.
+// The client has its own transport, so call CloseIdleConnections when it is no longer used.
+func (c *Client) HTTPClient(p *Parsed) *http.Client {
+	base := c.Transport
+	if base == nil {
+		if dt, ok := http.DefaultTransport.(*http.Transport); ok {
+			base = dt
+		} else {
+			base = &http.Transport{Proxy: http.ProxyFromEnvironment}
+		}
+	}
+	tr := base.Clone()
+	t := p.Transfer
+	if t == nil {
+		t = &Transfer{MaxRedirects: defaultMaxRedirects}
+	}
+
+	if t.Insecure {
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{} //nolint:gosec
+		}
+		tr.TLSClientConfig.InsecureSkipVerify = true //nolint:gosec // -k/--insecure
+	}
+	if t.Proxy != nil {
+		tr.Proxy = http.ProxyURL(t.Proxy)
+	}
+	if t.ConnectTimeout > 0 || len(t.Resolve) > 0 {
+		// Keep the dialer of the base transport such as a unix socket dialer
+		dial := tr.DialContext
+		if dial == nil {
+			dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+			dial = dialer.DialContext
+		}
+		tr.DialContext = timeoutDialer(resolveDialer(dial, t.Resolve), t.ConnectTimeout)
+	}
+
+	return &http.Client{
+		Transport:     tr,
+		Timeout:       t.MaxTime,
+		CheckRedirect: redirectPolicy(t),
+	}
+}
+
+// idleClosingBody is a response body that closes the idle connections of the client when closed.
+type idleClosingBody struct {
+	io.ReadCloser
+	client *http.Client
+}
+
+func (b *idleClosingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.client.CloseIdleConnections()
+	return err
+}
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// resolveDialer returns a dial function that connects to the addresses in resolve instead of resolving the host.
+func resolveDialer(dial dialFunc, resolve map[string][]string) dialFunc {
+	if len(resolve) == 0 {
+		return dial
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dial(ctx, network, addr)
+		}
+		addrs, ok := resolve[net.JoinHostPort(strings.ToLower(host), port)]
+		if !ok {
+			addrs, ok = resolve[net.JoinHostPort("*", port)]
+		}
+		if !ok {
+			return dial(ctx, network, addr)
+		}
+		var errs []error
+		for _, a := range addrs {
+			conn, err := dial(ctx, network, net.JoinHostPort(a, port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
+	}
+}
+
+// timeoutDialer returns a dial function that fails when connecting takes longer than timeout.
+func timeoutDialer(dial dialFunc, timeout time.Duration) dialFunc {
+	if timeout <= 0 {
+		return dial
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return dial(ctx, network, addr)
+	}
+}
+
+// redirectPolicy returns the CheckRedirect of http.Client for -L/--location and --max-redirs.
+func redirectPolicy(t *Transfer) func(*http.Request, []*http.Request) error {
+	return func(_ *http.Request, via []*http.Request) error {
+		if !t.FollowRedirects {
+			return http.ErrUseLastResponse
+		}
+		if t.MaxRedirects >= 0 && len(via) > t.MaxRedirects {
+			return fmt.Errorf("curlreq: %w: maximum (%d) redirects followed", ErrTooManyRedirects, t.MaxRedirects)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,290 @@
+package curlreq_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k1LoW/curlreq"
+)
+
+func TestDo(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/redirect/{n}", func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		if _, err := fmt.Sscan(r.PathValue("n"), &n); err != nil || n <= 0 {
+			_, _ = io.WriteString(w, "done")
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/redirect/%d", n-1), http.StatusFound)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		_, _ = fmt.Fprintf(w, "%s %s %s %s", r.Method, r.Host, r.Header.Get("X-A"), b)
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	tlsServer := httptest.NewUnstartedServer(mux)
+	tlsServer.Config.ErrorLog = log.New(io.Discard, "", 0)
+	tlsServer.StartTLS()
+	t.Cleanup(tlsServer.Close)
+	_, port, err := net.SplitHostPort(ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		cmd        string
+		wantStatus int
+		wantBody   string
+		wantErr    error
+	}{
+		{
+			name:       "request",
+			cmd:        `curl -H 'X-A: 1' -d a=b ` + ts.URL + `/echo`,
+			wantStatus: http.StatusOK,
+			wantBody:   "POST " + ts.Listener.Addr().String() + " 1 a=b",
+		},
+		{
+			name:       "redirects are not followed by default",
+			cmd:        `curl ` + ts.URL + `/redirect/1`,
+			wantStatus: http.StatusFound,
+			wantBody:   "<a href=\"/redirect/0\">Found</a>.\n\n",
+		},
+		{
+			name:       "follow redirects",
+			cmd:        `curl -L ` + ts.URL + `/redirect/3`,
+			wantStatus: http.StatusOK,
+			wantBody:   "done",
+		},
+		{
+			name:       "follow redirects with --location-trusted",
+			cmd:        `curl --location-trusted ` + ts.URL + `/redirect/2`,
+			wantStatus: http.StatusOK,
+			wantBody:   "done",
+		},
+		{
+			name:       "max redirects",
+			cmd:        `curl -L --max-redirs 3 ` + ts.URL + `/redirect/3`,
+			wantStatus: http.StatusOK,
+			wantBody:   "done",
+		},
+		{
+			name:    "too many redirects",
+			cmd:     `curl -L --max-redirs 2 ` + ts.URL + `/redirect/3`,
+			wantErr: curlreq.ErrTooManyRedirects,
+		},
+		{
+			name:       "insecure",
+			cmd:        `curl -k ` + tlsServer.URL + `/redirect/0`,
+			wantStatus: http.StatusOK,
+			wantBody:   "done",
+		},
+		{
+			name:       "resolve",
+			cmd:        `curl --resolve api.example.com:` + port + `:127.0.0.1 http://api.example.com:` + port + `/echo`,
+			wantStatus: http.StatusOK,
+			wantBody:   "GET api.example.com:" + port + "  ",
+		},
+		{
+			name:       "proxy",
+			cmd:        `curl -x ` + ts.URL + ` http://api.example.com/echo`,
+			wantStatus: http.StatusOK,
+			wantBody:   "GET api.example.com  ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := curlreq.Parse(tt.cmd)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			resp, err := p.Do(t.Context())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Do returned error: %v", err)
+			}
+			defer resp.Body.Close()
+			b, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if string(b) != tt.wantBody {
+				t.Errorf("got body %q, want %q", b, tt.wantBody)
+			}
+		})
+	}
+
+	t.Run("certificate is verified without -k", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := curlreq.Parse(`curl ` + tlsServer.URL + `/redirect/0`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp, err := p.Do(t.Context()); err == nil {
+			resp.Body.Close()
+			t.Error("Do should return error for unknown certificate")
+		}
+	})
+
+	t.Run("max time", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := curlreq.Parse(`curl -m 0.1 ` + ts.URL + `/slow`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		resp, err := p.Do(t.Context())
+		if err == nil {
+			resp.Body.Close()
+			t.Fatal("Do should return error on timeout")
+		}
+		var ue *url.Error
+		if !errors.As(err, &ue) || !ue.Timeout() {
+			t.Errorf("got %v, want timeout error", err)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("timeout took %s", elapsed)
+		}
+	})
+
+	t.Run("context", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := curlreq.Parse(`curl ` + ts.URL + `/slow`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+		resp, err := p.Do(ctx)
+		if err == nil {
+			resp.Body.Close()
+			t.Fatal("Do should return error on canceled context")
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("dialer of client transport", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu     sync.Mutex
+			dialed []string
+		)
+		d := &net.Dialer{}
+		c := &curlreq.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				mu.Lock()
+				dialed = append(dialed, addr)
+				mu.Unlock()
+				return d.DialContext(ctx, network, addr)
+			},
+		}}
+		p, err := curlreq.Parse(`curl --connect-timeout 5 --resolve api.example.com:` + port + `:127.0.0.1 http://api.example.com:` + port + `/echo`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := c.Do(t.Context(), p)
+		if err != nil {
+			t.Fatalf("Do returned error: %v", err)
+		}
+		resp.Body.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		if want := []string{"127.0.0.1:" + port}; !slices.Equal(dialed, want) {
+			t.Errorf("got dialed %v, want %v", dialed, want)
+		}
+	})
+
+	t.Run("connect timeout", func(t *testing.T) {
+		t.Parallel()
+
+		c := &curlreq.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				// Never connects
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		}}
+		p, err := curlreq.Parse(`curl --connect-timeout 0.1 http://api.example.com/`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		start := time.Now()
+		resp, err := c.Do(t.Context(), p)
+		if err == nil {
+			resp.Body.Close()
+			t.Fatal("Do should return error on connect timeout")
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("timeout took %s", elapsed)
+		}
+	})
+
+	t.Run("client transport", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := curlreq.Parse(`curl -H 'X-A: 1' ` + tlsServer.URL + `/echo`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		base, ok := tlsServer.Client().Transport.(*http.Transport)
+		if !ok {
+			t.Fatal("unexpected transport")
+		}
+		c := &curlreq.Client{Transport: base}
+		resp, err := c.Do(t.Context(), p)
+		if err != nil {
+			t.Fatalf("Do returned error: %v", err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(b), "GET ") {
+			t.Errorf("got body %q", b)
+		}
+		if c.HTTPClient(p).Timeout != 0 {
+			t.Error("client should have no timeout without -m")
+		}
+	})
+}
@@ -0,0 +1,184 @@
+// Command curlreq parses a curl command and prints the parsed request.
+//
+// Usage:
+//
+//	curlreq [flags] [curl command]
+//
+// The curl command is read from the arguments, the file given by --file, or stdin when neither is given.
+// It exits with status 1 when the command cannot be parsed and 2 on invalid usage.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http/httputil"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/k1LoW/curlreq"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+var formats = []string{"json", "command", "http"}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("curlreq", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(stderr, "Usage: curlreq [flags] [curl command]\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	var (
+		format = fs.String("format", "json", "output format ("+strings.Join(formats, ", ")+")")
+		file   = fs.String("file", "", "read the curl command from the file (- for stdin)")
+		wd     = fs.String("wd", "", "working directory for files referenced by the command")
+		all    = fs.Bool("all", false, "print all requests of the command as a list")
+		strict = fs.Bool("strict", false, "fail on unknown options")
+	)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if !slices.Contains(formats, *format) {
+		_, _ = fmt.Fprintf(stderr, "curlreq: unknown format %q\n", *format)
+		return exitUsage
+	}
+	if *file != "" && fs.NArg() > 0 {
+		_, _ = fmt.Fprintln(stderr, "curlreq: --file cannot be used with a command in arguments")
+		return exitUsage
+	}
+
+	var opts []curlreq.Option
+	if *wd != "" {
+		opts = append(opts, curlreq.WithWorkingDirectory(*wd))
+	}
+	if *strict {
+		opts = append(opts, curlreq.WithStrict())
+	}
+	cmd := fs.Args()
+	if len(cmd) == 0 {
+		b, err := readCommand(*file, stdin)
+		if err != nil {
+			printError(stderr, err)
+			return exitError
+		}
+		cmd = []string{string(b)}
+	}
+	if len(fs.Args()) > 0 || (*file != "" && *file != "-") {
+		// Stdin is available to the command (-d @-, -T -) only when the command is not read from it
+		opts = append(opts, curlreq.WithStdin(stdin))
+	}
+
+	p, err := curlreq.NewParser(opts...)
+	if err != nil {
+		printError(stderr, err)
+		return exitUsage
+	}
+	parsed, err := parse(p, cmd, *all)
+	if err == nil {
+		err = checkURLs(parsed)
+	}
+	if err != nil {
+		printError(stderr, err)
+		return exitError
+	}
+	if err := write(stdout, parsed, *format, *all); err != nil {
+		printError(stderr, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// readCommand reads a curl command from the file, or from stdin when the file is empty or "-".
+func readCommand(file string, stdin io.Reader) ([]byte, error) {
+	if file == "" || file == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(file) //nolint:gosec
+}
+
+// parse parses the command with ParseAll when all is true, or with Parse otherwise.
+func parse(p *curlreq.Parser, cmd []string, all bool) ([]*curlreq.Parsed, error) {
+	if all {
+		return p.ParseAll(cmd...)
+	}
+	parsed, err := p.Parse(cmd...)
+	if err != nil {
+		return nil, err
+	}
+	return []*curlreq.Parsed{parsed}, nil
+}
+
+// checkURLs returns ErrMissingURL when a parsed request has no URL, which could not be sent.
+func checkURLs(parsed []*curlreq.Parsed) error {
+	for _, p := range parsed {
+		if p.URL == nil {
+			return curlreq.ErrMissingURL
+		}
+	}
+	return nil
+}
+
+// write prints the parsed requests in the format.
+func write(w io.Writer, parsed []*curlreq.Parsed, format string, all bool) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if all {
+			return enc.Encode(parsed)
+		}
+		return enc.Encode(parsed[0])
+	case "command":
+		for _, p := range parsed {
+			if _, err := fmt.Fprintln(w, p.Command()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "http":
+		for i, p := range parsed {
+			req, err := p.Request()
+			if err != nil {
+				return err
+			}
+			b, err := httputil.DumpRequest(req, true)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				b = append([]byte("\n"), b...)
+			}
+			if _, err := w.Write(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// printError prints err prefixed with the command name unless the error from curlreq already has it.
+func printError(w io.Writer, err error) {
+	msg := err.Error()
+	if !strings.HasPrefix(msg, "curlreq: ") {
+		msg = "curlreq: " + msg
+	}
+	_, _ = fmt.Fprintln(w, msg)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "data.txt"), []byte("a=b"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cmd.txt"), []byte("curl \\\n  -X PUT \\\n  https://api.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stdin.txt"), []byte("curl -d @- https://api.example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name: "json",
+			args: []string{"curl -H 'X-A: 1' https://api.example.com"},
+			wantStdout: `{
+  "url": "https://api.example.com",
+  "method": "GET",
+  "header": {
+    "X-A": [
+      "1"
+    ]
+  }
+}
+`,
+		},
+		{
+			name:       "command from arguments",
+			args:       []string{"--format", "command", "curl", "-d", "x=y", "https://api.example.com"},
+			wantStdout: "curl --data-binary x=y https://api.example.com\n",
+		},
+		{
+			name:       "command from stdin",
+			args:       []string{"--format=command"},
+			stdin:      "curl -X DELETE https://api.example.com",
+			wantStdout: "curl -X DELETE https://api.example.com\n",
+		},
+		{
+			name:       "command from file",
+			args:       []string{"--format", "command", "--file", filepath.Join(dir, "cmd.txt")},
+			wantStdout: "curl -X PUT https://api.example.com\n",
+		},
+		{
+			name:       "stdin for the command",
+			args:       []string{"--format", "command", "curl -d @- https://api.example.com"},
+			stdin:      "from=stdin",
+			wantStdout: "curl --data-binary from=stdin https://api.example.com\n",
+		},
+		{
+			name:       "stdin for the command from file",
+			args:       []string{"--format", "command", "--file", filepath.Join(dir, "stdin.txt")},
+			stdin:      "from=stdin",
+			wantStdout: "curl --data-binary from=stdin https://api.example.com\n",
+		},
+		{
+			name:       "working directory",
+			args:       []string{"--format", "http", "--wd", dir, "curl -d @data.txt https://api.example.com/items"},
+			wantStdout: "POST /items HTTP/1.1\r\nHost: api.example.com\r\nContent-Type: application/x-www-form-urlencoded\r\n\r\na=b",
+		},
+		{
+			name:       "all",
+			args:       []string{"--all", "--format", "command", "curl https://api.example.com/[1-2]"},
+			wantStdout: "curl https://api.example.com/1\ncurl https://api.example.com/2\n",
+		},
+		{
+			name:       "parse error",
+			args:       []string{"curl -X"},
+			wantCode:   exitError,
+			wantStderr: "curlreq: -X: option requires a value (argument 1: \"-X\")\n",
+		},
+		{
+			name:       "strict",
+			args:       []string{"--strict", "curl --hedaer 'X-A: 1' https://api.example.com"},
+			wantCode:   exitError,
+			wantStderr: "curlreq: --hedaer: unknown option (argument 1: \"--hedaer\")\ncurlreq: unexpected argument (argument 2: \"X-A: 1\")\n",
+		},
+		{
+			name:       "missing URL",
+			args:       []string{"curl", "-H", "A: b"},
+			wantCode:   exitError,
+			wantStderr: "curlreq: no URL specified\n",
+		},
+		{
+			name:       "missing URL in command format",
+			args:       []string{"--format", "command", "curl -H 'A: b'"},
+			wantCode:   exitError,
+			wantStderr: "curlreq: no URL specified\n",
+		},
+		{
+			name:       "missing URL in http format",
+			args:       []string{"--format", "http", "--all", "curl -H 'A: b'"},
+			wantCode:   exitError,
+			wantStderr: "curlreq: no URL specified\n",
+		},
+		{
+			name:     "missing file",
+			args:     []string{"--file", filepath.Join(dir, "missing.txt")},
+			wantCode: exitError,
+		},
+		{
+			name:       "unknown format",
+			args:       []string{"--format", "yaml", "curl https://api.example.com"},
+			wantCode:   exitUsage,
+			wantStderr: "curlreq: unknown format \"yaml\"\n",
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"--unknown", "curl https://api.example.com"},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "file and arguments",
+			args:     []string{"--file", "cmd.txt", "curl https://api.example.com"},
+			wantCode: exitUsage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("got exit code %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+			if diff := cmp.Diff(tt.wantStdout, stdout.String()); diff != "" {
+				t.Errorf("unexpected stdout (-want +got):\n%s", diff)
+			}
+			if tt.wantStderr != "" {
+				if diff := cmp.Diff(tt.wantStderr, stderr.String()); diff != "" {
+					t.Errorf("unexpected stderr (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
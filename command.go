@@ -0,0 +1,311 @@
+package curlreq
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Command returns a curl command line that reproduces the request.
+func (p *Parsed) Command() string {
+	args := p.args()
+	quoted := make([]string, 0, len(args)+1)
+	quoted = append(quoted, "curl")
+	for _, a := range args {
+		quoted = append(quoted, shellQuote(a))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// String returns a curl command line that reproduces the request.
+func (p *Parsed) String() string {
+	return p.Command()
+}
+
+// args returns the arguments of curl command (without "curl") that reproduce the request.
+func (p *Parsed) args() []string {
+	var args []string
+
+	hasBody := len(p.Body) > 0 || p.BodyFile != nil || len(p.Form) > 0
+	method := p.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	switch {
+	case method == http.MethodHead && !hasBody:
+		args = append(args, "-I")
+	case method == http.MethodPost && hasBody:
+	case method == http.MethodGet && !hasBody:
+	default:
+		args = append(args, "-X", method)
+	}
+
+	// curl sends the body of --data-binary with the form Content-Type by default
+	implicitContentType := (len(p.Body) > 0 || p.BodyFile != nil) && len(p.Header.Values("Content-Type")) == 1
+	for _, f := range p.headerFields() {
+		if implicitContentType && strings.EqualFold(f.Name, "Content-Type") && f.Value == "application/x-www-form-urlencoded" {
+			continue
+		}
+		if f.Value == "" {
+			// "Name:" would remove the header
+			args = append(args, "-H", f.Name+";")
+			continue
+		}
+		args = append(args, "-H", fmt.Sprintf("%s: %s", f.Name, f.Value))
+	}
+	for _, k := range p.RemovedHeaders {
+		args = append(args, "-H", k+":")
+	}
+
+	if len(p.Body) > 0 {
+		if p.Body[0] == '@' {
+			// --data-binary would read the file
+			args = append(args, "--data-raw", string(p.Body))
+		} else {
+			args = append(args, "--data-binary", string(p.Body))
+		}
+	}
+
+	if p.BodyFile != nil {
+		args = append(args, "--data-binary", "@"+p.BodyFile.Path)
+	}
+
+	for _, part := range p.Form {
+		args = append(args, formArgs(part)...)
+	}
+
+	if p.Output != "" {
+		args = append(args, "-o", p.Output)
+	}
+
+	if p.Transfer != nil {
+		args = append(args, p.Transfer.args()...)
+	}
+
+	if p.URL != nil {
+		if hasGlobChars(p.URL) {
+			args = append(args, "-g")
+		}
+		args = append(args, p.URL.String())
+	}
+
+	return args
+}
+
+// hasGlobChars reports whether the URL has characters that curl would treat as URL globbing.
+// Brackets of an IPv6 host are not globbing.
+func hasGlobChars(u *url.URL) bool {
+	s := strings.TrimPrefix(u.String(), u.Scheme+"://"+u.Host)
+	return strings.ContainsAny(u.Host, "{}") || strings.ContainsAny(s, "[]{}")
+}
+
+// formArgs returns the arguments of curl command that reproduce the form part.
+func formArgs(part *FormPart) []string {
+	if part.Filename == "" && part.ContentType == "" && len(part.Header) == 0 {
+		return []string{"--form-string", part.Name + "=" + string(part.Value)}
+	}
+	var b strings.Builder
+	b.WriteString(part.Name)
+	b.WriteString(`="`)
+	b.WriteString(quoteEscaper.Replace(string(part.Value)))
+	b.WriteString(`"`)
+	if part.ContentType != "" {
+		b.WriteString(";type=")
+		b.WriteString(part.ContentType)
+	}
+	if part.Filename != "" {
+		b.WriteString(`;filename="`)
+		b.WriteString(quoteEscaper.Replace(part.Filename))
+		b.WriteString(`"`)
+	}
+	keys := make([]string, 0, len(part.Header))
+	for k := range part.Header {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range part.Header[k] {
+			b.WriteString(`;headers="`)
+			b.WriteString(quoteEscaper.Replace(fmt.Sprintf("%s: %s", k, v)))
+			b.WriteString(`"`)
+		}
+	}
+	return []string{"-F", b.String()}
+}
+
+// shellQuote quotes s for POSIX shells. Strings containing binary or control characters use ANSI-C quoting ($'...').
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if !strings.ContainsFunc(s, isShellUnsafe) {
+		return s
+	}
+	if !utf8.ValidString(s) || strings.ContainsFunc(s, isControl) {
+		return ansiCQuote(s)
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ansiCQuote quotes s using ANSI-C quoting ($'...').
+func ansiCQuote(s string) string {
+	var b strings.Builder
+	b.WriteString("$'")
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' || c == '\'':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteString("'")
+	return b.String()
+}
+
+// decodeANSIC decodes the content of ANSI-C quoting ($'...').
+func decodeANSIC(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 >= len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'e', 'E':
+			b.WriteByte(0x1b)
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte('\v')
+		case '\\', '\'', '"', '?':
+			b.WriteByte(s[i])
+		case 'x':
+			n, v := 0, byte(0)
+			for ; n < 2 && i+1 < len(s) && isHex(s[i+1]); n++ {
+				i++
+				v = v<<4 | unhex(s[i])
+			}
+			if n == 0 {
+				b.WriteString(`\x`)
+				continue
+			}
+			b.WriteByte(v)
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			v := s[i] - '0'
+			for n := 1; n < 3 && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '7'; n++ {
+				i++
+				v = v<<3 | (s[i] - '0')
+			}
+			b.WriteByte(v)
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// replaceANSICQuotes replaces ANSI-C quoted strings ($'...') in the command line with placeholders,
+// because shellwords does not support them and cannot hold binary data.
+// The returned replacer restores the decoded strings in one pass, so decoded strings that look like
+// placeholders are kept as is.
+func replaceANSICQuotes(line string) (string, *strings.Replacer) {
+	if !strings.Contains(line, "$'") {
+		return line, nil
+	}
+	var (
+		b            strings.Builder
+		oldnew       []string
+		singleQuoted bool
+		doubleQuoted bool
+	)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && !singleQuoted && i+1 < len(line):
+			b.WriteByte(c)
+			b.WriteByte(line[i+1])
+			i++
+			continue
+		case c == '\'' && !doubleQuoted:
+			singleQuoted = !singleQuoted
+		case c == '"' && !singleQuoted:
+			doubleQuoted = !doubleQuoted
+		case c == '$' && !singleQuoted && !doubleQuoted && i+1 < len(line) && line[i+1] == '\'':
+			j := i + 2
+			for ; j < len(line) && line[j] != '\''; j++ {
+				if line[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(line) {
+				// Unterminated quoting is reported by shellwords
+				b.WriteString(line[i:])
+				return b.String(), strings.NewReplacer(oldnew...)
+			}
+			placeholder := fmt.Sprintf("\x00%d\x00", len(oldnew)/2)
+			oldnew = append(oldnew, placeholder, decodeANSIC(line[i+2:j]))
+			b.WriteString(placeholder)
+			i = j
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), strings.NewReplacer(oldnew...)
+}
+
+func isShellUnsafe(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	case strings.ContainsRune("_@%+=:,./-", r):
+		return false
+	default:
+		return true
+	}
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 && r != '\n' && r != '\t' || r == 0x7f
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
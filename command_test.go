@@ -0,0 +1,155 @@
+package curlreq_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/k1LoW/curlreq"
+)
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   *curlreq.Parsed
+		want string
+	}{
+		{
+			name: "GET",
+			in: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/sloths?page=2"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+			want: `curl 'https://api.example.com/sloths?page=2'`,
+		},
+		{
+			name: "HEAD",
+			in: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodHead,
+				Header: http.Header{},
+			},
+			want: `curl -I https://api.example.com`,
+		},
+		{
+			name: "POST with headers",
+			in: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{
+					"X-B":          []string{"it's"},
+					"X-A":          []string{"1", "2"},
+					"Content-Type": []string{"application/json"},
+				},
+				Body: []byte(`{"name":"sloth"}`),
+			},
+			want: `curl -H 'Content-Type: application/json' -H 'X-A: 1' -H 'X-A: 2' -H 'X-B: it'\''s' --data-binary '{"name":"sloth"}' https://api.example.com`,
+		},
+		{
+			name: "PUT with body starting with @",
+			in: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPut,
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				Body:   []byte(`@sloth`),
+			},
+			want: `curl -X PUT --data-raw @sloth https://api.example.com`,
+		},
+		{
+			name: "binary body",
+			in: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				Body:   []byte{0x00, 'a', '\'', '\n', 0xFF},
+			},
+			want: `curl --data-binary $'\x00a\'\n\xff' https://api.example.com`,
+		},
+		{
+			name: "GET with body",
+			in: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodGet,
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				Body:   []byte("a=b"),
+			},
+			want: `curl -X GET --data-binary a=b https://api.example.com`,
+		},
+		{
+			name: "form",
+			in: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{},
+				Form: []*curlreq.FormPart{
+					{Name: "name", Value: []byte("John;Doe")},
+					{Name: "file", Value: []byte(`say "hi"`), Filename: "a.txt", ContentType: "text/plain", Header: http.Header{"X-A": []string{"1"}}},
+				},
+			},
+			want: `curl --form-string 'name=John;Doe' -F 'file="say \"hi\"";type=text/plain;filename="a.txt";headers="X-A: 1"' https://api.example.com`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tt.in.Command()
+			if got != tt.want {
+				t.Errorf("got %s\nwant %s", got, tt.want)
+			}
+			if got != tt.in.String() {
+				t.Errorf("String() should return the same as Command(): %s", tt.in.String())
+			}
+			assertRoundTrip(t, tt.in)
+		})
+	}
+}
+
+func TestParseWithANSICQuoting(t *testing.T) {
+	t.Parallel()
+
+	got, err := curlreq.Parse(`curl --data-binary $'\x00\101\tb\'c\\' -H $'X-A: "1"' https://api.example.com`)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	want := &curlreq.Parsed{
+		URL:    URL(t, "https://api.example.com"),
+		Method: http.MethodPost,
+		Header: http.Header{
+			"Content-Type": []string{"application/x-www-form-urlencoded"},
+			"X-A":          []string{`"1"`},
+		},
+		Body: []byte("\x00A\tb'c\\"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected result (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseWithANSICQuotingLikePlaceholder(t *testing.T) {
+	t.Parallel()
+
+	// The decoded binary data looks like the placeholder of the second ANSI-C quoted argument
+	in := &curlreq.Parsed{
+		URL:    URL(t, "https://api.example.com"),
+		Method: http.MethodPost,
+		Header: http.Header{
+			"Content-Type": []string{"application/x-www-form-urlencoded"},
+			"X-A":          []string{"a\tb"},
+		},
+		Body: []byte("\x001\x00\x000\x00"),
+	}
+	for range 20 {
+		got, err := curlreq.Parse(`curl --data-binary $'\x001\x00\x000\x00' -H $'X-A: a\tb' https://api.example.com`)
+		if err != nil {
+			t.Fatalf("Parse returned error: %v", err)
+		}
+		if diff := cmp.Diff(in, got); diff != "" {
+			t.Fatalf("unexpected result (-want +got):\n%s", diff)
+		}
+	}
+	assertRoundTrip(t, in)
+}
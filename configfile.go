@@ -0,0 +1,172 @@
+package curlreq
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// maxConfigDepth is the maximum depth of config files including other config files.
+const maxConfigDepth = 10
+
+// expandConfigFiles replaces -K/--config options with the arguments read from the config files.
+// The arguments from a config file have the position of the file name argument.
+func expandConfigFiles(in []string, pos []int, files *fileReader, c *cmdline, depth int) ([]string, []int, error) {
+	var (
+		args    []string
+		argsPos []int
+	)
+	for i := 0; i < len(in); i++ {
+		spec, _, ok := lookupOption(in[i])
+		if !ok || spec.arity == argNone || i+1 >= len(in) {
+			args = append(args, in[i])
+			argsPos = append(argsPos, pos[i])
+			continue
+		}
+		if spec.long != "config" {
+			// Keep the value as is
+			args = append(args, in[i], in[i+1])
+			argsPos = append(argsPos, pos[i], pos[i+1])
+			i++
+			continue
+		}
+		if depth >= maxConfigDepth {
+			return nil, nil, c.newError(pos[i+1], in[i], errors.New("config files are nested too deeply"))
+		}
+		b, err := files.read(in[i+1])
+		if err != nil {
+			return nil, nil, c.newError(pos[i+1], in[i], err)
+		}
+		cfgArgs, _ := rewrite(parseConfig(string(b)))
+		cfgPos := make([]int, len(cfgArgs))
+		for j := range cfgPos {
+			cfgPos[j] = pos[i+1]
+		}
+		cfgArgs, cfgPos, err = expandConfigFiles(cfgArgs, cfgPos, files, c, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		args = append(args, cfgArgs...)
+		argsPos = append(argsPos, cfgPos...)
+		i++
+	}
+	return args, argsPos, nil
+}
+
+// parseConfig parses the content of a curl config file into command line arguments.
+// Each line is an option and its value separated by whitespace, '=' or ':' such as `header = "X-A: 1"`.
+// Options can be written without leading dashes, and a quoted value can contain \", \\, \t, \n, \r and \v escapes.
+func parseConfig(content string) []string {
+	var args []string
+	for line := range strings.Lines(content) {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" || strings.IndexByte("#/*\r\n", line[0]) >= 0 {
+			// Comment or empty line
+			continue
+		}
+		dashed := line[0] == '-'
+		end := strings.IndexFunc(line, func(r rune) bool {
+			return isConfigSpace(r) || !dashed && (r == '=' || r == ':')
+		})
+		if end < 0 {
+			end = len(line)
+		}
+		name := line[:end]
+		if !dashed {
+			name = "--" + name
+		}
+		rest := strings.TrimLeftFunc(line[end:], func(r rune) bool {
+			return isConfigSpace(r) || !dashed && (r == '=' || r == ':')
+		})
+
+		args = append(args, name)
+		if spec, _, ok := lookupOption(name); ok && spec.arity == argNone {
+			// The value of a boolean option is ignored
+			continue
+		}
+		if strings.HasPrefix(rest, `"`) {
+			args = append(args, unquoteConfigValue(rest[1:]))
+			continue
+		}
+		if i := strings.IndexFunc(rest, isConfigSpace); i >= 0 {
+			rest = rest[:i]
+		}
+		if rest != "" {
+			args = append(args, rest)
+		}
+	}
+	return args
+}
+
+// unquoteConfigValue returns the value of a double-quoted string in a config file, s without the opening quote.
+func unquoteConfigValue(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\n' || c == '\r':
+			return b.String()
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 't':
+				b.WriteByte('\t')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 'v':
+				b.WriteByte('\v')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func isConfigSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
+
+// curlrcArgs returns the arguments read from the curlrc file to be prepended to the command.
+// If path is empty, the default curlrc is looked up as curl does, and it is ignored when it does not exist.
+func curlrcArgs(path string, files *fileReader) ([]string, error) {
+	if path != "" {
+		b, err := files.read(path)
+		if err != nil {
+			return nil, err
+		}
+		args, _ := rewrite(parseConfig(string(b)))
+		return args, nil
+	}
+	for _, p := range defaultCurlrcPaths() {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		args, _ := rewrite(parseConfig(string(b)))
+		return args, nil
+	}
+	return nil, nil
+}
+
+// defaultCurlrcPaths returns the candidates of the default curlrc in the order curl looks up.
+// The file in $XDG_CONFIG_HOME is "curlrc" without the leading dot.
+func defaultCurlrcPaths() []string {
+	var paths []string
+	for _, c := range []struct{ env, name string }{
+		{"CURL_HOME", ".curlrc"},
+		{"XDG_CONFIG_HOME", "curlrc"},
+		{"HOME", ".curlrc"},
+	} {
+		if dir := os.Getenv(c.env); dir != "" {
+			paths = append(paths, filepath.Join(dir, c.name))
+		}
+	}
+	return slices.Compact(paths)
+}
@@ -0,0 +1,220 @@
+package curlreq_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/k1LoW/curlreq"
+)
+
+func TestParseWithConfigFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		config string
+		cmd    string
+		want   *curlreq.Parsed
+	}{
+		{
+			name: "option = value",
+			config: `# headers
+header = "Authorization: Bearer token"
+header="X-A: \"quoted\"\ttab"
+user-agent: sloth
+-X PUT
+--data a=b
+silent
+url = "https://api.example.com/sloths"
+`,
+			cmd: `curl -K config.txt`,
+			want: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/sloths"),
+				Method: http.MethodPut,
+				Header: http.Header{
+					"Authorization": []string{"Bearer token"},
+					"X-A":           []string{"\"quoted\"\ttab"},
+					"User-Agent":    []string{"sloth"},
+					"Content-Type":  []string{"application/x-www-form-urlencoded"},
+				},
+				Body: []byte("a=b"),
+			},
+		},
+		{
+			name: "unquoted value ends at whitespace",
+			config: `  request   DELETE ignored
+   / comment
+url example.com/a
+`,
+			cmd: `curl -H 'X-A: 1' --config config.txt`,
+			want: &curlreq.Parsed{
+				URL:    URL(t, "http://example.com/a"),
+				Method: http.MethodDelete,
+				Header: http.Header{"X-A": []string{"1"}},
+			},
+		},
+		{
+			name:   "options after config file win",
+			config: "request = PUT\n",
+			cmd:    `curl --config=config.txt -X PATCH https://api.example.com`,
+			want: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPatch,
+				Header: http.Header{},
+			},
+		},
+		{
+			name:   "data file in config file",
+			config: "data = @data.txt\n",
+			cmd:    `curl -K config.txt https://api.example.com`,
+			want: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				Body:   []byte("from=file"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "config.txt"), []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "data.txt"), []byte("from=file"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			p, err := curlreq.NewParser(curlreq.WithWorkingDirectory(dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Parse(tt.cmd)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("missing config file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := curlreq.Parse(`curl -K does-not-exist https://api.example.com`)
+		if !errors.Is(err, curlreq.ErrFileRead) {
+			t.Fatalf("got %v, want %v", err, curlreq.ErrFileRead)
+		}
+		var pe *curlreq.ParseError
+		if !errors.As(err, &pe) || pe.Index != 2 || pe.Option != "-K" {
+			t.Errorf("got %#v, want error at argument 2 of -K", pe)
+		}
+	})
+
+	t.Run("recursive config file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "config.txt"), []byte("config = config.txt\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		p, err := curlreq.NewParser(curlreq.WithWorkingDirectory(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Parse(`curl -K config.txt https://api.example.com`); err == nil {
+			t.Error("Parse should return error for recursive config file")
+		}
+	})
+}
+
+func TestWithCurlrc(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".curlrc"), []byte("header = \"X-Curlrc: 1\"\nuser-agent = curlrc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		cmd  string
+		want http.Header
+	}{
+		{
+			name: "explicit path",
+			path: filepath.Join(dir, ".curlrc"),
+			cmd:  `curl -A cmd https://api.example.com`,
+			want: http.Header{"X-Curlrc": []string{"1"}, "User-Agent": []string{"curlrc", "cmd"}},
+		},
+		{
+			name: "default path",
+			cmd:  `curl https://api.example.com`,
+			want: http.Header{"X-Curlrc": []string{"1"}, "User-Agent": []string{"curlrc"}},
+		},
+		{
+			name: "disabled by -q",
+			cmd:  `curl -q https://api.example.com`,
+			want: http.Header{},
+		},
+	}
+	t.Setenv("CURL_HOME", dir)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := curlreq.NewParser(curlreq.WithCurlrc(tt.path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Parse(tt.cmd)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got.Header); diff != "" {
+				t.Errorf("unexpected header (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("XDG_CONFIG_HOME", func(t *testing.T) {
+		xdg := t.TempDir()
+		if err := os.WriteFile(filepath.Join(xdg, "curlrc"), []byte("header = \"X-Xdg: 1\"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		// .curlrc in XDG_CONFIG_HOME is not read
+		if err := os.WriteFile(filepath.Join(xdg, ".curlrc"), []byte("header = \"X-Dot: 1\"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("CURL_HOME", "")
+		t.Setenv("XDG_CONFIG_HOME", xdg)
+		t.Setenv("HOME", dir)
+		p, err := curlreq.NewParser(curlreq.WithCurlrc(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := p.Parse(`curl https://api.example.com`)
+		if err != nil {
+			t.Fatalf("Parse returned error: %v", err)
+		}
+		if diff := cmp.Diff(http.Header{"X-Xdg": []string{"1"}}, got.Header); diff != "" {
+			t.Errorf("unexpected header (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("no default curlrc", func(t *testing.T) {
+		t.Setenv("CURL_HOME", "")
+		t.Setenv("XDG_CONFIG_HOME", "")
+		t.Setenv("HOME", t.TempDir())
+		p, err := curlreq.NewParser(curlreq.WithCurlrc(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Parse(`curl https://api.example.com`); err != nil {
+			t.Errorf("Parse returned error: %v", err)
+		}
+	})
+}
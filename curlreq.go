@@ -4,38 +4,56 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
-	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/mattn/go-shellwords"
 )
 
-const (
-	stateBlank  = ""
-	stateHeader = "header"
-	stateUA     = "user-agent"
-	stateData   = "data"
-	stateUser   = "user"
-	stateMethod = "method"
-	stateCookie = "cookie"
-)
-
 type Parsed struct {
 	URL    *url.URL
 	Method string
 	Header http.Header
-	Body   []byte
+	// RawHeaders is the headers in the order and case of the command. It is set when WithRawHeaders is used,
+	// and used instead of Header by Command and MarshalJSON as long as it is consistent with Header.
+	RawHeaders []HeaderField
+	Body       []byte
+	// BodyFile is the body read from a file when the request is sent. It is set instead of Body when WithStreamingBody is used.
+	BodyFile *BodyFile
+	Form     []*FormPart
+	// RemovedHeaders is the list of header names removed by -H "Name:". The headers are not sent even if curl adds them by default.
+	RemovedHeaders []string
+	// Output is the file name to save the response specified by -o/--output.
+	Output string
+	// Transfer is the options of how the request is sent such as -L and --max-time. nil if the command has none.
+	Transfer *Transfer
+	// Unknown holds unknown options, unsupported options with their values and unexpected arguments ignored while parsing.
+	Unknown []string
 }
 
 type config struct {
-	wd string
+	wd              string
+	strict          bool
+	globLimit       int
+	defaultScheme   string
+	useCurlrc       bool
+	curlrc          string
+	stdin           io.Reader
+	fsys            fs.FS
+	policy          FileAccessPolicy
+	streaming       bool
+	streamThreshold int64
+	implicitHeaders ImplicitHeader
+	rawHeaders      bool
 }
 
 type Option func(*config) error
@@ -46,99 +64,201 @@ type Parser struct {
 
 func NewParser(opts ...Option) (*Parser, error) {
 	c := &config{
-		wd: ".",
+		wd:              ".",
+		globLimit:       defaultGlobLimit,
+		implicitHeaders: ImplicitContentType,
 	}
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
 			return nil, err
 		}
 	}
+	if err := c.validateWorkingDirectory(); err != nil {
+		return nil, err
+	}
 	return &Parser{
 		config: c,
 	}, nil
 }
 
+// Parse a curl command. When the command has multiple URLs, the last URL of the first group
+// (before --next) is used. Use ParseAll to get all requests.
 func (p *Parser) Parse(cmd ...string) (*Parsed, error) {
-	args, err := cmdToArgs(cmd...)
+	groups, err := p.parse(cmd...)
 	if err != nil {
 		return nil, err
 	}
-	// Expand @file syntax in data parameters
-	args, err = expandCurlDataFiles(args, p.config.wd)
+	first := groups[0]
+	return first[len(first)-1], nil
+}
+
+// ParseAll parses a curl command and returns a request per URL.
+// Options are applied to every URL in the same group, and --next (-:) starts a new group.
+func (p *Parser) ParseAll(cmd ...string) ([]*Parsed, error) {
+	groups, err := p.parse(cmd...)
 	if err != nil {
 		return nil, err
 	}
+	var all []*Parsed
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	return all, nil
+}
 
-	out := newParsed()
-	state := stateBlank
+// parse parses a curl command into groups of requests separated by --next.
+func (p *Parser) parse(cmd ...string) ([][]*Parsed, error) {
+	args, pos, c, err := cmdToArgs(cmd...)
+	if err != nil {
+		return nil, err
+	}
+	files := p.config.newFileReader()
+	if p.config.useCurlrc && args[0] != "-q" && args[0] != "--disable" {
+		rc, err := curlrcArgs(p.config.curlrc, files)
+		if err != nil {
+			return nil, c.newError(-1, "", err)
+		}
+		rcPos := slices.Repeat([]int{-1}, len(rc))
+		args, pos = append(rc, args...), append(rcPos, pos...)
+	}
+	// Expand -K/--config files
+	args, pos, err = expandConfigFiles(args, pos, files, c, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups [][]*Parsed
+	for {
+		group, n, err := p.parseGroup(args, pos, files, c)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+		if n >= len(args) {
+			break
+		}
+		// Skip --next
+		args, pos = args[n+1:], pos[n+1:]
+	}
+	// Groups without URL around --next (e.g. "curl -: URL" or "curl URL --next") send nothing
+	nonEmpty := slices.DeleteFunc(slices.Clone(groups), func(g []*Parsed) bool {
+		return g[0].URL == nil
+	})
+	if len(nonEmpty) == 0 {
+		return groups[:1], nil
+	}
+	return nonEmpty, nil
+}
 
-	for _, a := range args {
+// parseGroup parses arguments until --next and returns the requests and the number of consumed arguments.
+func (p *Parser) parseGroup(args []string, pos []int, files *fileReader, c *cmdline) ([]*Parsed, int, error) {
+	st := &parseState{
+		out:             newParsed(),
+		files:           files,
+		customHeaders:   map[string]bool{},
+		implicitHeaders: p.config.implicitHeaders,
+		rawHeaders:      p.config.rawHeaders,
+		defaultScheme:   p.config.defaultScheme,
+	}
+	var (
+		pending     *optionSpec
+		option      string
+		optionIndex int
+		unknown     []int
+		// unsupported is the index of unsupported options in unknown, and values is the index of their values
+		unsupported = map[int]bool{}
+		values      = map[int]bool{}
+		n           int
+	)
+
+loop:
+	for ; n < len(args); n++ {
+		a := args[n]
+		if pending != nil {
+			if err := st.applyValue(pending, a, pos[n]); err != nil {
+				return nil, 0, c.newError(pos[n], option, err)
+			}
+			pending = nil
+			continue
+		}
+		spec, negated, ok := lookupOption(a)
 		switch {
-		case isURL(a):
-			u, err := url.Parse(a)
-			if err != nil {
-				return nil, err
+		case ok && spec.long == "next":
+			break loop
+		case ok && unsupportedOptions[spec.long] && !negated:
+			unknown = append(unknown, n)
+			unsupported[n] = true
+			if spec.arity == argNone {
+				break
 			}
-			out.URL = u
-		case a == "-A" || a == "--user-agent":
-			state = stateUA
-		case a == "-H" || a == "--header":
-			state = stateHeader
-		case a == "-d" || a == "--data" || a == "--data-ascii" || a == "--data-raw" || a == "--data-binary" || a == "--data-urlencode":
-			state = stateData
-		case a == "-u" || a == "--user":
-			state = stateUser
-		case a == "-I" || a == "--head":
-			out.Method = http.MethodHead
-		case a == "-X" || a == "--request":
-			state = stateMethod
-		case a == "-b" || a == "--cookie":
-			state = stateCookie
-		case a == "--compressed":
-			if out.Header.Get("Accept-Encoding") == "" {
-				out.Header.Add("Accept-Encoding", "deflate, gzip")
+			if n+1 >= len(args) {
+				return nil, 0, c.newError(pos[n], a, ErrMissingOptionValue)
 			}
-		case a != "":
-			switch state {
-			case stateHeader:
-				k, v := parseField(a)
-				out.Header.Add(k, v)
-				state = stateBlank
-			case stateUA:
-				out.Header.Add("User-Agent", a)
-				state = stateBlank
-			case stateData:
-				if out.Method == http.MethodGet || out.Method == http.MethodHead {
-					out.Method = http.MethodPost
-				}
+			n++
+			unknown = append(unknown, n)
+			values[n] = true
+		case ok && spec.arity == argNone:
+			st.applyFlag(spec, negated)
+		case ok:
+			pending, option, optionIndex = spec, a, pos[n]
+		case isURL(a):
+			st.addURL(a, pos[n], "")
+		case strings.TrimSpace(a) == "":
+			// Leftovers of line continuation
+		default:
+			unknown = append(unknown, n)
+		}
+	}
 
-				if len(out.Body) == 0 {
-					out.Body = []byte(a)
+	if pending != nil {
+		return nil, 0, c.newError(optionIndex, option, ErrMissingOptionValue)
+	}
+
+	if len(unknown) > 0 {
+		if p.config.strict {
+			errs := make([]error, 0, len(unknown))
+			for _, i := range unknown {
+				switch {
+				case values[i]:
+					continue
+				case unsupported[i]:
+					errs = append(errs, c.newError(pos[i], args[i], ErrUnsupportedOption))
+					continue
+				}
+				if strings.HasPrefix(args[i], "-") && len(args[i]) > 1 {
+					errs = append(errs, c.newError(pos[i], args[i], ErrUnknownOption))
 				} else {
-					out.Body = append(out.Body, '&')
-					out.Body = append(out.Body, []byte(a)...)
+					errs = append(errs, c.newError(pos[i], "", ErrUnexpectedArgument))
 				}
-
-				state = stateBlank
-			case stateUser:
-				out.Header.Add("Authorization", fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(a))))
-				state = stateBlank
-			case stateMethod:
-				out.Method = a
-				state = stateBlank
-			case stateCookie:
-				out.Header.Add("Cookie", a)
-				state = stateBlank
-			default:
 			}
+			return nil, 0, errors.Join(errs...)
+		}
+		for _, i := range unknown {
+			st.out.Unknown = append(st.out.Unknown, args[i])
 		}
 	}
 
-	if len(out.Body) > 0 && out.Header.Get("Content-Type") != "" {
-		out.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	targets, err := st.expandURLs(p.config.globLimit, c)
+	if err != nil {
+		return nil, 0, err
 	}
-
-	return out, nil
+	if len(targets) == 0 {
+		out, err := st.build(nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []*Parsed{out}, n, nil
+	}
+	group := make([]*Parsed, 0, len(targets))
+	for _, t := range targets {
+		out, err := st.build(t.url)
+		if err != nil {
+			return nil, 0, err
+		}
+		out.Output = t.output
+		group = append(group, out)
+	}
+	return group, n, nil
 }
 
 func WithWorkingDirectory(path string) Option {
@@ -146,16 +266,89 @@ func WithWorkingDirectory(path string) Option {
 		if path == "" {
 			return fmt.Errorf("base path cannot be empty")
 		}
-		if fi, err := os.Stat(path); err != nil {
-			return fmt.Errorf("invalid base path: %w", err)
-		} else if !fi.IsDir() {
-			return fmt.Errorf("base path is not a directory")
-		}
 		c.wd = path
 		return nil
 	}
 }
 
+// WithStrict makes the parser return an error for unknown options, unsupported options that change the request
+// (such as -e/--referer and --digest) and unexpected arguments instead of recording them in Parsed.Unknown.
+func WithStrict() Option {
+	return func(c *config) error {
+		c.strict = true
+		return nil
+	}
+}
+
+// WithGlobLimit sets the maximum number of URLs expanded from a URL glob (default 10000).
+func WithGlobLimit(n int) Option {
+	return func(c *config) error {
+		if n <= 0 {
+			return fmt.Errorf("glob limit must be positive: %d", n)
+		}
+		c.globLimit = n
+		return nil
+	}
+}
+
+// WithDefaultScheme sets the scheme used for URLs without a scheme like --proto-default.
+// Without it, the scheme is guessed from the host name as curl does (ftp for ftp.example.com, http otherwise).
+func WithDefaultScheme(scheme string) Option {
+	return func(c *config) error {
+		if !isScheme(scheme) {
+			return fmt.Errorf("invalid scheme: %q", scheme)
+		}
+		c.defaultScheme = strings.ToLower(scheme)
+		return nil
+	}
+}
+
+// WithCurlrc loads the curlrc file before the command as curl does. If path is empty, the default curlrc
+// ($CURL_HOME/.curlrc, $XDG_CONFIG_HOME/curlrc or $HOME/.curlrc) is loaded if it exists.
+// The default curlrc is read from the OS filesystem, so WithFS and WithFileAccessPolicy do not apply to it.
+// The curlrc is not loaded when the first option is -q/--disable.
+func WithCurlrc(path string) Option {
+	return func(c *config) error {
+		c.useCurlrc = true
+		c.curlrc = path
+		return nil
+	}
+}
+
+// WithStdin sets the reader used for stdin referenced by @- (-d @-, -F file=@-), -T - and -K -.
+// Stdin can be referenced only once in a command.
+func WithStdin(r io.Reader) Option {
+	return func(c *config) error {
+		c.stdin = r
+		return nil
+	}
+}
+
+// WithFS sets the filesystem used to read files referenced by the command (@file, -F, -K, -T and -H @file).
+// The working directory set by WithWorkingDirectory is a directory in the filesystem.
+func WithFS(fsys fs.FS) Option {
+	return func(c *config) error {
+		if fsys == nil {
+			return errors.New("filesystem must not be nil")
+		}
+		c.fsys = fsys
+		return nil
+	}
+}
+
+// WithStreamingBody keeps files of --data-binary @file and -T file not smaller than threshold bytes as Parsed.BodyFile
+// instead of reading them into Parsed.Body. The file is read when the request is sent.
+func WithStreamingBody(threshold int64) Option {
+	return func(c *config) error {
+		if threshold < 0 {
+			return fmt.Errorf("streaming threshold must not be negative: %d", threshold)
+		}
+		c.streaming = true
+		c.streamThreshold = threshold
+		return nil
+	}
+}
+
 // NewRequest returns *http.Request created by parsing a curl command.
 func NewRequest(cmd ...string) (*http.Request, error) {
 	p, err := Parse(cmd...)
@@ -174,58 +367,484 @@ func Parse(cmd ...string) (*Parsed, error) {
 	return p.Parse(cmd...)
 }
 
+// FromRequest returns *Parsed created from *http.Request. The body of req is restored after reading.
+func FromRequest(req *http.Request) (*Parsed, error) {
+	if req.URL == nil {
+		return nil, fmt.Errorf("curlreq: %w", ErrMissingURL)
+	}
+	out := newParsed()
+	if req.Method != "" {
+		out.Method = req.Method
+	}
+	u := *req.URL
+	if u.Host == "" {
+		// Server requests have only the path in URL
+		u.Host = req.Host
+	}
+	if u.Scheme == "" {
+		u.Scheme = "http"
+		if req.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+	out.URL = &u
+	if req.Header != nil {
+		out.Header = req.Header.Clone()
+	}
+	if req.Host != "" && req.Host != u.Host {
+		out.Header.Set("Host", req.Host)
+	}
+
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	out.Body = body
+	return out, nil
+}
+
+// CommandFromRequest returns a curl command line that reproduces *http.Request.
+func CommandFromRequest(req *http.Request) (string, error) {
+	p, err := FromRequest(req)
+	if err != nil {
+		return "", err
+	}
+	return p.Command(), nil
+}
+
+// ParseAll parses a curl command and returns a request per URL.
+func ParseAll(cmd ...string) ([]*Parsed, error) {
+	p, err := NewParser()
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseAll(cmd...)
+}
+
 // Request returns *http.Request.
-func (p *Parsed) Request() (*http.Request, error) {
+func (p *Parsed) Request(opts ...RequestOption) (*http.Request, error) {
+	rc := &requestConfig{}
+	for _, opt := range opts {
+		opt(rc)
+	}
 	var b io.Reader
 	if p.URL == nil {
-		return nil, fmt.Errorf("curlreq: invalid URL: %s", p.URL)
+		return nil, fmt.Errorf("curlreq: %w", ErrMissingURL)
 	}
-	if len(p.Body) == 0 {
+	header := p.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	switch {
+	case len(p.Form) > 0:
+		body, ct, err := buildMultipartBody(p.Form)
+		if err != nil {
+			return nil, err
+		}
+		b = bytes.NewReader(body)
+		// Respect user-specified multipart/* type, but the boundary must match the generated body
+		if v := header.Get("Content-Type"); v == "" {
+			header.Set("Content-Type", ct)
+		} else if !strings.Contains(v, "boundary=") {
+			_, params, _ := strings.Cut(ct, ";")
+			header.Set("Content-Type", v+";"+params)
+		}
+	case p.BodyFile != nil:
+		f, err := p.BodyFile.Open()
+		if err != nil {
+			return nil, err
+		}
+		b = f
+	case len(p.Body) == 0:
 		b = http.NoBody
-	} else {
+	default:
 		b = bytes.NewReader(p.Body)
 	}
 	req, err := http.NewRequest(p.Method, p.URL.String(), b)
 	if err != nil {
+		if c, ok := b.(io.Closer); ok {
+			// Do not leak the opened body file
+			_ = c.Close()
+		}
 		return nil, err
 	}
-	req.Header = p.Header
+	if p.BodyFile != nil {
+		// Stream the file and reopen it on redirects and retries
+		req.ContentLength = p.BodyFile.Size
+		req.GetBody = p.BodyFile.Open
+	}
+	if rc.rawHeaderNames {
+		header = p.rawHeaderNames(header)
+	}
+	req.Header = header
 	return req, nil
 }
 
 func (p *Parsed) MarshalJSON() ([]byte, error) {
-	// Check if Body contains valid UTF-8
-	bodyEncoding := ""
-	bodyValue := ""
-
-	if len(p.Body) > 0 {
-		if !utf8.Valid(p.Body) {
-			// Body contains invalid UTF-8, encode as base64
-			bodyEncoding = "base64"
-			bodyValue = base64.StdEncoding.EncodeToString(p.Body)
-		} else {
-			// Body contains valid UTF-8
-			bodyEncoding = "plain"
-			bodyValue = string(p.Body)
-		}
+	bodyValue, bodyEncoding := encodeBody(p.Body)
+	var rawHeaders []HeaderField
+	if p.rawHeadersConsistent() {
+		rawHeaders = p.RawHeaders
+	}
+	var u string
+	if p.URL != nil {
+		u = p.URL.String()
 	}
 
 	s := struct {
-		URL          string      `json:"url"`
-		Method       string      `json:"method"`
-		Header       http.Header `json:"header"`
-		Body         string      `json:"body,omitempty"`
-		BodyEncoding string      `json:"body_encoding,omitempty"`
+		URL            string        `json:"url"`
+		Method         string        `json:"method"`
+		Header         http.Header   `json:"header"`
+		RawHeaders     []HeaderField `json:"raw_headers,omitempty"`
+		Body           string        `json:"body,omitempty"`
+		BodyEncoding   string        `json:"body_encoding,omitempty"`
+		BodyFile       *BodyFile     `json:"body_file,omitempty"`
+		Form           []*FormPart   `json:"form,omitempty"`
+		RemovedHeaders []string      `json:"removed_headers,omitempty"`
+		Output         string        `json:"output,omitempty"`
+		Transfer       *Transfer     `json:"transfer,omitempty"`
 	}{
-		URL:          p.URL.String(),
-		Method:       p.Method,
-		Header:       p.Header,
-		Body:         bodyValue,
-		BodyEncoding: bodyEncoding,
+		URL:            u,
+		Method:         p.Method,
+		Header:         p.Header,
+		RawHeaders:     rawHeaders,
+		Body:           bodyValue,
+		BodyEncoding:   bodyEncoding,
+		BodyFile:       p.BodyFile,
+		Form:           p.Form,
+		RemovedHeaders: p.RemovedHeaders,
+		Output:         p.Output,
+		Transfer:       p.Transfer,
 	}
 	return json.Marshal(s)
 }
 
+// rawURL is a URL in the command before URL globbing is expanded.
+type rawURL struct {
+	value  string
+	index  int
+	option string
+}
+
+// target is a URL to request and the file name to save the response.
+type target struct {
+	url    *url.URL
+	output string
+}
+
+// parseState is the state of parsing a curl command.
+type parseState struct {
+	out             *Parsed
+	urls            []rawURL
+	outputs         []string
+	queries         []string
+	files           *fileReader
+	uploadFile      string
+	defaultScheme   string
+	globoff         bool
+	get             bool
+	head            bool
+	methodSpecified bool
+	jsonData        bool
+	// customHeaders is the set of header names specified by -H with a value.
+	customHeaders map[string]bool
+	// formData is whether the body is sent by -d/--data options.
+	formData        bool
+	implicitHeaders ImplicitHeader
+	rawHeaders      bool
+}
+
+// applyFlag applies the boolean option. Options that do not affect the request are ignored.
+func (s *parseState) applyFlag(spec *optionSpec, negated bool) {
+	switch spec.long {
+	case "head":
+		if !s.methodSpecified && !negated {
+			s.out.Method = http.MethodHead
+		}
+		s.head = !negated
+	case "get":
+		s.get = !negated
+	case "globoff":
+		s.globoff = !negated
+	case "location", "location-trusted", "insecure":
+		s.applyTransferFlag(spec, negated)
+	case "compressed":
+		if !negated && s.out.Header.Get("Accept-Encoding") == "" {
+			s.addHeaderField(s.out, "Accept-Encoding", "deflate, gzip")
+		}
+	}
+}
+
+// applyValue applies the option that takes a value. Options that do not affect the request are ignored.
+func (s *parseState) applyValue(spec *optionSpec, a string, index int) error {
+	out := s.out
+	switch spec.long {
+	case "header":
+		if name, ok := dataFile(a); ok {
+			// -H @file reads one header per line
+			b, err := s.files.read(name)
+			if err != nil {
+				return err
+			}
+			for line := range strings.Lines(string(b)) {
+				s.addHeader(strings.TrimRight(line, "\r\n"))
+			}
+			break
+		}
+		s.addHeader(a)
+	case "user-agent":
+		s.addHeaderField(out, "User-Agent", a)
+	case "data", "data-ascii", "data-binary", "data-raw", "data-urlencode":
+		s.setPostMethod()
+		s.formData = true
+		if name, ok := dataFile(a); ok && spec.long == "data-binary" && out.BodyFile == nil && len(out.Body) == 0 {
+			bf, err := s.files.bodyFile(name)
+			if err != nil {
+				return err
+			}
+			if bf != nil {
+				out.BodyFile = bf
+				break
+			}
+		}
+		v, err := s.dataValue(spec.long, a)
+		if err != nil {
+			return err
+		}
+		if err := readBodyFile(out); err != nil {
+			return err
+		}
+		if len(out.Body) == 0 {
+			out.Body = []byte(v)
+		} else {
+			out.Body = append(out.Body, '&')
+			out.Body = append(out.Body, []byte(v)...)
+		}
+	case "json":
+		s.setPostMethod()
+		v, err := s.dataValue(spec.long, a)
+		if err != nil {
+			return err
+		}
+		if err := readBodyFile(out); err != nil {
+			return err
+		}
+		// Multiple --json are concatenated without separator
+		out.Body = append(out.Body, []byte(v)...)
+		s.jsonData = true
+	case "user":
+		if a != "" {
+			s.addHeaderField(out, "Authorization", fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(a))))
+		}
+	case "request":
+		if a != "" {
+			out.Method = a
+			s.methodSpecified = true
+		}
+	case "cookie":
+		if a != "" {
+			s.addHeaderField(out, "Cookie", a)
+		}
+	case "form", "form-string":
+		part, err := parseFormPart(a, spec.long == "form-string", s.files)
+		if err != nil {
+			return err
+		}
+		s.setPostMethod()
+		out.Form = append(out.Form, part)
+	case "url":
+		// --url is a URL even if it does not look like a URL
+		s.addURL(a, index, "--url")
+	case "url-query":
+		q, err := s.dataValue(spec.long, a)
+		if err != nil {
+			return err
+		}
+		s.queries = append(s.queries, q)
+	case "upload-file":
+		bf, err := s.files.bodyFile(a)
+		if err != nil {
+			return err
+		}
+		out.Body, out.BodyFile = nil, bf
+		s.formData = false
+		if bf == nil {
+			b, err := s.files.read(a)
+			if err != nil {
+				return err
+			}
+			out.Body = b
+		}
+		// HTTP upload uses PUT
+		if !s.methodSpecified {
+			out.Method = http.MethodPut
+		}
+		s.uploadFile = a
+	case "output":
+		s.outputs = append(s.outputs, a)
+	case "max-redirs", "max-time", "connect-timeout", "proxy", "resolve":
+		return s.applyTransferValue(spec, a)
+	case "proto-default":
+		if !isScheme(a) {
+			return fmt.Errorf("invalid scheme: %q", a)
+		}
+		s.defaultScheme = strings.ToLower(a)
+	}
+	return nil
+}
+
+// addHeader adds the header of -H. "Name:" removes the header curl adds, "Name;" adds an empty header,
+// and malformed headers are ignored.
+func (s *parseState) addHeader(h string) {
+	out := s.out
+	if name, ok := strings.CutSuffix(h, ";"); ok && !strings.Contains(h, ":") {
+		if name = strings.TrimSpace(name); name != "" {
+			s.addHeaderField(out, name, "")
+			s.customHeaders[http.CanonicalHeaderKey(name)] = true
+		}
+		return
+	}
+	name, v, ok := parseField(h)
+	if !ok || name == "" {
+		return
+	}
+	k := http.CanonicalHeaderKey(name)
+	if v == "" {
+		if !slices.Contains(out.RemovedHeaders, k) {
+			out.RemovedHeaders = append(out.RemovedHeaders, k)
+		}
+		return
+	}
+	s.addHeaderField(out, name, v)
+	s.customHeaders[k] = true
+}
+
+func (s *parseState) addURL(a string, index int, option string) {
+	s.urls = append(s.urls, rawURL{value: a, index: index, option: option})
+}
+
+// expandURLs parses the URLs of the group, expanding URL globs unless -g/--globoff is specified.
+// The n-th -o/--output is used for the n-th URL, and #N in it is replaced with the value of the N-th glob.
+func (s *parseState) expandURLs(limit int, c *cmdline) ([]target, error) {
+	var targets []target
+	for k, raw := range s.urls {
+		output := ""
+		if k < len(s.outputs) {
+			output = s.outputs[k]
+		}
+		value := s.withScheme(raw.value)
+		expansions := []globExpansion{{url: value}}
+		if !s.globoff {
+			var err error
+			expansions, err = expandGlob(value, limit)
+			if err != nil {
+				return nil, c.newError(raw.index, raw.option, err)
+			}
+		}
+		for _, e := range expansions {
+			u, err := url.Parse(e.url)
+			if err != nil {
+				return nil, c.newError(raw.index, raw.option, err)
+			}
+			targets = append(targets, target{url: u, output: substituteGlob(output, e.values)})
+		}
+	}
+	return targets, nil
+}
+
+// build returns the request for the URL with the options of the group applied.
+func (s *parseState) build(u *url.URL) (*Parsed, error) {
+	out := s.out.clone()
+	out.URL = u
+
+	if u != nil {
+		for _, q := range s.queries {
+			appendQuery(u, q)
+		}
+		if s.uploadFile != "" && s.uploadFile != "-" && (u.Path == "" || strings.HasSuffix(u.Path, "/")) {
+			// curl appends the file name to the URL without a file part
+			u.Path = strings.TrimSuffix(u.Path, "/") + "/" + filepath.Base(s.uploadFile)
+		}
+	}
+
+	if (len(out.Body) > 0 || out.BodyFile != nil) && len(out.Form) > 0 {
+		return nil, &ParseError{Index: -1, Option: "-F", Offset: -1, Err: errors.New("data and form options cannot be used together")}
+	}
+
+	if s.get {
+		if len(out.Form) > 0 {
+			return nil, &ParseError{Index: -1, Option: "-G", Offset: -1, Err: errors.New("-G/--get and form options cannot be used together")}
+		}
+		if err := readBodyFile(out); err != nil {
+			return nil, &ParseError{Index: -1, Option: "-G", Offset: -1, Err: err}
+		}
+		if err := moveDataToQuery(out, s.head, s.methodSpecified); err != nil {
+			return nil, &ParseError{Index: -1, Option: "-G", Offset: -1, Err: err}
+		}
+	}
+
+	if s.jsonData {
+		// --json sets headers only when they are not specified by -H
+		s.setDefaultHeader(out, "Content-Type", "application/json")
+		s.setDefaultHeader(out, "Accept", "application/json")
+	}
+
+	s.addImplicitHeaders(out)
+
+	// -H "Name:" removes the header added by options such as -A and --json, and implicit headers
+	for _, k := range out.RemovedHeaders {
+		if !s.customHeaders[k] {
+			s.removeHeader(out, k)
+		}
+	}
+
+	return out, nil
+}
+
+// readBodyFile reads the lazy body of out into Body so that data can be appended to it.
+func readBodyFile(out *Parsed) error {
+	if out.BodyFile == nil {
+		return nil
+	}
+	rc, err := out.BodyFile.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return fmt.Errorf("%w %s: %w", ErrFileRead, out.BodyFile.Path, err)
+	}
+	out.Body, out.BodyFile = b, nil
+	return nil
+}
+
+// setPostMethod changes the method to POST as curl does when sending data, unless the method is specified by -X.
+func (s *parseState) setPostMethod() {
+	if !s.methodSpecified && (s.out.Method == http.MethodGet || s.out.Method == http.MethodHead) {
+		s.out.Method = http.MethodPost
+	}
+}
+
+// clone returns a copy of p that does not share headers, body and form with p.
+func (p *Parsed) clone() *Parsed {
+	c := *p
+	if p.URL != nil {
+		u := *p.URL
+		c.URL = &u
+	}
+	c.Header = p.Header.Clone()
+	c.Body = slices.Clone(p.Body)
+	c.Form = slices.Clone(p.Form)
+	c.RemovedHeaders = slices.Clone(p.RemovedHeaders)
+	c.RawHeaders = slices.Clone(p.RawHeaders)
+	c.Unknown = slices.Clone(p.Unknown)
+	if p.Transfer != nil {
+		c.Transfer = p.Transfer.clone()
+	}
+	return &c
+}
+
 func newParsed() *Parsed {
 	return &Parsed{
 		Method: http.MethodGet,
@@ -233,213 +852,339 @@ func newParsed() *Parsed {
 	}
 }
 
-func cmdToArgs(cmd ...string) ([]string, error) {
-	var err error
+// cmdToArgs splits the curl command into arguments (without "curl").
+// pos holds the index of each argument in the original command.
+func cmdToArgs(cmd ...string) ([]string, []int, *cmdline, error) {
+	c := &cmdline{}
 	if len(cmd) == 1 {
-		cmd, err = shellwords.Parse(cmd[0])
+		line, restore := replaceANSICQuotes(cmd[0])
+		words, err := shellwords.Parse(line)
 		if err != nil {
-			return nil, err
+			return nil, nil, c, c.newError(-1, "", err)
+		}
+		if restore != nil {
+			for i := range words {
+				words[i] = restore.Replace(words[i])
+			}
 		}
+		if offsets := argOffsets(cmd[0]); len(offsets) == len(words) {
+			c.offsets = offsets
+		}
+		cmd = words
+	}
+	c.args = cmd
+	if len(cmd) == 0 {
+		return nil, nil, c, c.newError(-1, "", ErrNotCurl)
 	}
 	if cmd[0] != "curl" {
-		return nil, fmt.Errorf("invalid curl command: %s", cmd)
+		return nil, nil, c, c.newError(0, "", ErrNotCurl)
 	}
 	if len(cmd) == 1 {
-		return nil, fmt.Errorf("invalid curl command: %s", cmd)
+		return nil, nil, c, c.newError(-1, "", ErrMissingURL)
 	}
 
-	return rewrite(cmd[1:]), nil
+	args, pos := rewrite(cmd[1:])
+	return args, pos, c, nil
 }
 
-func rewrite(args []string) []string {
+// rewrite splits combined short options (e.g. -sSL, -XPOST, -HAccept:json) and --option=value
+// into separate arguments following curl's option grammar. Values of options are never split.
+func rewrite(args []string) ([]string, []int) {
 	rw := []string{}
-	for _, a := range args {
-		if strings.HasPrefix(a, "-X") {
-			rw = append(rw, a[0:2])
-			rw = append(rw, a[2:])
-		} else {
+	pos := []int{}
+	expectValue := false
+	for i, a := range args {
+		switch {
+		case expectValue || len(a) <= 2 || a[0] != '-':
+			rw = append(rw, a)
+			pos = append(pos, i+1)
+			spec, _, ok := lookupOption(a)
+			expectValue = !expectValue && ok && spec.arity != argNone
+		case a[1] == '-':
+			// --option=value
+			name, value, found := strings.Cut(a, "=")
+			if spec, _, ok := lookupOption(name); ok && found && spec.arity != argNone {
+				rw = append(rw, name, value)
+				pos = append(pos, i+1, i+1)
+				expectValue = false
+				continue
+			}
 			rw = append(rw, a)
+			pos = append(pos, i+1)
+			spec, _, ok := lookupOption(a)
+			expectValue = ok && spec.arity != argNone
+		default:
+			split, needValue := splitShortOptions(a)
+			rw = append(rw, split...)
+			for range split {
+				pos = append(pos, i+1)
+			}
+			expectValue = needValue
 		}
 	}
-	return rw
+	return rw, pos
 }
 
-func isURL(u string) bool {
-	return strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://")
+// splitShortOptions splits a cluster of short options. needValue is true when the last option takes a value
+// that is not glued to the cluster.
+func splitShortOptions(a string) (split []string, needValue bool) {
+	for j := 1; j < len(a); j++ {
+		spec, ok := shortOptions[a[j]]
+		if !ok {
+			if j == 1 {
+				return []string{a}, false
+			}
+			// Leave the rest as an unknown option
+			return append(split, "-"+a[j:]), false
+		}
+		split = append(split, "-"+string(a[j]))
+		if spec.arity != argNone {
+			if j+1 < len(a) {
+				return append(split, a[j+1:]), false
+			}
+			return split, true
+		}
+	}
+	return split, false
 }
 
-func parseField(a string) (string, string) {
-	i := strings.Index(a, ":")
-	return strings.TrimSpace(a[0:i]), strings.TrimSpace(a[i+1:])
+// moveDataToQuery appends the data to the query string of the URL as curl does with -G/--get.
+func moveDataToQuery(out *Parsed, head, methodSpecified bool) error {
+	if !methodSpecified {
+		if head {
+			out.Method = http.MethodHead
+		} else {
+			out.Method = http.MethodGet
+		}
+	}
+	if len(out.Body) == 0 {
+		return nil
+	}
+	if out.URL == nil {
+		return ErrMissingURL
+	}
+	appendQuery(out.URL, string(out.Body))
+	out.Body = nil
+	return nil
 }
 
-// expandCurlDataFiles recognizes the @file syntax in data parameters and expands its content.
-func expandCurlDataFiles(in []string, wd string) ([]string, error) {
-	args := slices.Clone(in)
-	for i := 0; i < len(args); {
-		opt, value, inline, ok := parseCurlDataArg(args[i])
-		if !ok {
-			i++
-			continue
-		}
+// appendQuery appends the query q to the query of u.
+func appendQuery(u *url.URL, q string) {
+	if q == "" {
+		return
+	}
+	if u.RawQuery == "" {
+		u.RawQuery = q
+	} else {
+		u.RawQuery = u.RawQuery + "&" + q
+	}
+}
 
-		isUrlEncode := opt == "--data-urlencode"
+// withScheme prepends the default scheme to the URL without a scheme.
+// If the default scheme is not specified, it is guessed from the host name as curl does.
+func (s *parseState) withScheme(u string) string {
+	if scheme, _, ok := strings.Cut(u, "://"); ok && isScheme(scheme) {
+		return u
+	}
+	scheme := s.defaultScheme
+	if scheme == "" {
+		scheme = guessScheme(u)
+	}
+	return scheme + "://" + u
+}
 
-		if inline {
-			step := 1
-			processed, err := processDataValue(value, wd, isUrlEncode)
-			if err != nil {
-				return nil, err
-			}
-			if processed != "" {
-				args[i] = opt
-				args = slices.Insert(args, i+1, processed)
-				step = 2
-			}
-			i += step
-			continue
+// guessScheme guesses the scheme of the URL without a scheme from the host name as curl does.
+func guessScheme(u string) string {
+	host := strings.ToLower(u)
+	for _, scheme := range []string{"ftp", "dict", "ldap", "imap", "smtp", "pop3"} {
+		if strings.HasPrefix(host, scheme+".") {
+			return scheme
 		}
+	}
+	return "http"
+}
 
-		if i+1 >= len(args) {
-			break
+// readRequestBody reads the body of req without consuming it.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
 		}
-
-		processed, err := processDataValue(args[i+1], wd, isUrlEncode)
+		defer rc.Close()
+		b, err := io.ReadAll(rc)
 		if err != nil {
 			return nil, err
 		}
-		if processed != "" {
-			args[i+1] = processed
+		if len(b) == 0 {
+			return nil, nil
 		}
-		i += 2
+		return b, nil
 	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Body.Close(); err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	return b, nil
+}
 
-	return args, nil
+// encodeBody returns the body as a string and its encoding ("plain" or "base64").
+func encodeBody(body []byte) (string, string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	if !utf8.Valid(body) {
+		// Body contains invalid UTF-8, encode as base64
+		return base64.StdEncoding.EncodeToString(body), "base64"
+	}
+	// Body contains valid UTF-8
+	return string(body), "plain"
 }
 
-// processDataValue processes data value for both @file expansion and URL encoding.
-func processDataValue(value string, wd string, urlEncode bool) (string, error) {
-	var result string
+// isURL reports whether u looks like a URL. A URL without a scheme such as example.com, myhost/path,
+// localhost:8080/path or [::1]:80 is also a URL as curl treats it.
+func isURL(u string) bool {
+	if scheme, _, ok := strings.Cut(u, "://"); ok {
+		return isScheme(scheme)
+	}
+	if u == "" || u[0] == '-' {
+		return false
+	}
+	host := u
+	if i := strings.IndexAny(host, "/?#"); i >= 0 {
+		host = host[:i]
+	}
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	if strings.HasPrefix(host, "[") {
+		// IPv6 address or URL glob
+		return strings.Contains(host, "]")
+	}
+	if h, port, ok := strings.Cut(host, ":"); ok {
+		if port == "" || strings.Trim(port, "0123456789") != "" {
+			return false
+		}
+		host = h
+	}
+	if host == "" || strings.HasPrefix(host, ".") {
+		return false
+	}
+	for _, r := range host {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-._{},[]", r) {
+			return false
+		}
+	}
+	return true
+}
 
-	// Check for name@file format (only for --data-urlencode)
-	if urlEncode {
-		if idx := strings.Index(value, "@"); idx > 0 && !strings.Contains(value[:idx], "=") {
-			// Format: name@file
-			name := value[:idx]
-			filePath := value[idx+1:]
-			content, err := readDataFile("@"+filePath, wd)
-			if err != nil {
-				return "", err
-			}
-			if len(content) > 0 {
-				result = name + "=" + string(content)
-			} else {
-				result = value
-			}
-		} else {
-			// Normal @file or regular data
-			content, err := readDataFile(value, wd)
-			if err != nil {
-				return "", err
-			}
-			if len(content) > 0 {
-				result = string(content)
-			} else {
-				result = value
-			}
+// isScheme reports whether s is a valid URL scheme.
+func isScheme(s string) bool {
+	if s == "" || !isLetter(s[:1]) {
+		return false
+	}
+	for _, r := range s {
+		if !isLetter(string(r)) && !unicode.IsDigit(r) && !strings.ContainsRune("+-.", r) {
+			return false
 		}
+	}
+	return true
+}
+
+func parseField(a string) (string, string, bool) {
+	k, v, ok := strings.Cut(a, ":")
+	return strings.TrimSpace(k), strings.TrimSpace(v), ok
+}
 
-		// Apply URL encoding
-		if result != "" {
-			result = urlEncodeData(result)
+// dataValue returns the data of the data option value as curl does.
+// -d/--data and --data-ascii read @file without CR and LF, --data-binary and --json read @file as is,
+// --data-raw does not treat @ specially, and --data-urlencode and --url-query URL-encode the content.
+func (s *parseState) dataValue(long, a string) (string, error) {
+	switch long {
+	case "data-raw":
+		return a, nil
+	case "data", "data-ascii":
+		name, ok := dataFile(a)
+		if !ok {
+			return a, nil
 		}
-	} else {
-		// Non-urlencode mode: just expand @file
-		content, err := readDataFile(value, wd)
+		b, err := s.files.read(name)
 		if err != nil {
 			return "", err
 		}
-		if len(content) > 0 {
-			result = string(content)
-		} else {
-			result = value
+		return newlineStripper.Replace(string(b)), nil
+	case "data-binary", "json":
+		name, ok := dataFile(a)
+		if !ok {
+			return a, nil
+		}
+		b, err := s.files.read(name)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	case "url-query":
+		if strings.HasPrefix(a, "+") {
+			// --url-query +data is added as is
+			return a[1:], nil
 		}
 	}
-
-	return result, nil
-}
-
-// readDataFile reads the content of a file if the value starts with @, returns nil otherwise.
-func readDataFile(value string, wd string) ([]byte, error) {
-	if !strings.HasPrefix(value, "@") || len(value) <= 1 {
-		return nil, nil
-	}
-	payloadPath := value[1:]
-
-	// Resolve relative path based on working directory
-	var fullPath string
-	if filepath.IsAbs(payloadPath) {
-		// Absolute path
-		fullPath = payloadPath
-	} else {
-		// Relative path - resolve against working directory
-		fullPath = filepath.Join(wd, payloadPath)
+	// --data-urlencode and --url-query
+	if i := strings.Index(a, "@"); i >= 0 && !strings.Contains(a[:i], "=") {
+		// Format: @file or name@file
+		b, err := s.files.read(a[i+1:])
+		if err != nil {
+			return "", err
+		}
+		if i == 0 {
+			return url.QueryEscape(string(b)), nil
+		}
+		return a[:i] + "=" + url.QueryEscape(string(b)), nil
 	}
+	return urlEncodeData(a), nil
+}
 
-	b, err := os.ReadFile(fullPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read %s: %w", payloadPath, err)
+// dataFile returns the file name of the @file syntax.
+func dataFile(value string) (string, bool) {
+	if len(value) <= 1 || value[0] != '@' {
+		return "", false
 	}
-	return b, nil
+	return value[1:], true
 }
 
+var newlineStripper = strings.NewReplacer("\r", "", "\n", "")
+
 // urlEncodeData applies URL encoding to data according to curl's --data-urlencode behavior.
 // Supported formats:
 // - "content" -> URL encode entire content
 // - "=content" -> URL encode content, prepend "="
-// - "name=content" -> Keep "name=", URL encode only content part
-// - "@file" -> URL encode file contents
-// - "name@file" -> Keep "name=", URL encode file contents.
+// - "name=content" -> Keep "name=", URL encode only content part.
 func urlEncodeData(data string) string {
 	// Format: =content
 	if strings.HasPrefix(data, "=") {
 		return "=" + url.QueryEscape(data[1:])
 	}
 
-	// Format: name=content or name@file (already processed by readDataFile)
+	// Format: name=content
 	if idx := strings.Index(data, "="); idx != -1 {
 		name := data[:idx]
 		content := data[idx+1:]
 		return name + "=" + url.QueryEscape(content)
 	}
 
-	// Format: content (no = or @)
+	// Format: content (no =)
 	return url.QueryEscape(data)
 }
-
-func parseCurlDataArg(arg string) (option, value string, inline, ok bool) {
-	switch {
-	case arg == "--data-binary":
-		return "--data-binary", "", false, true
-	case strings.HasPrefix(arg, "--data-binary="):
-		return "--data-binary", arg[len("--data-binary="):], true, true
-	case arg == "--data-ascii":
-		return "--data-ascii", "", false, true
-	case strings.HasPrefix(arg, "--data-ascii="):
-		return "--data-ascii", arg[len("--data-ascii="):], true, true
-	case arg == "--data-urlencode":
-		return "--data-urlencode", "", false, true
-	case strings.HasPrefix(arg, "--data-urlencode="):
-		return "--data-urlencode", arg[len("--data-urlencode="):], true, true
-	case arg == "--data":
-		return "--data", "", false, true
-	case strings.HasPrefix(arg, "--data="):
-		return "--data", arg[len("--data="):], true, true
-	case arg == "-d":
-		return "-d", "", false, true
-	case strings.HasPrefix(arg, "-d"):
-		return "-d", arg[len("-d"):], true, true
-	default:
-		return "", "", false, false
-	}
-}
@@ -1,6 +1,7 @@
 package curlreq_test
 
 import (
+	"bufio"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -41,9 +43,10 @@ func TestParse(t *testing.T) {
 		{
 			`curl -I http://api.sloths.com -vvv --foo --whatever bar`,
 			&curlreq.Parsed{
-				URL:    URL(t, "http://api.sloths.com"),
-				Method: http.MethodHead,
-				Header: http.Header{},
+				URL:     URL(t, "http://bar"),
+				Method:  http.MethodHead,
+				Header:  http.Header{},
+				Unknown: []string{"--foo", "--whatever"},
 			},
 		},
 		{
@@ -115,7 +118,7 @@ func TestParse(t *testing.T) {
 			&curlreq.Parsed{
 				URL:    URL(t, "https://api.sloths.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte("foo=bar"),
 			},
 		},
@@ -124,7 +127,7 @@ func TestParse(t *testing.T) {
 			&curlreq.Parsed{
 				URL:    URL(t, "https://api.sloths.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte("foo=bar&bar=baz"),
 			},
 		},
@@ -212,6 +215,7 @@ func TestParse(t *testing.T) {
 			if diff := cmp.Diff(got, tt.want, nil); diff != "" {
 				t.Errorf("%s", diff)
 			}
+			assertRoundTrip(t, got)
 		})
 	}
 }
@@ -224,7 +228,63 @@ func TestParseExtra(t *testing.T) {
 		{
 			`curl example.com`,
 			&curlreq.Parsed{
-				URL:    nil,
+				URL:    URL(t, "http://example.com"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl localhost:8080/path?q=1`,
+			&curlreq.Parsed{
+				URL:    URL(t, "http://localhost:8080/path?q=1"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl [::1]:80/path`,
+			&curlreq.Parsed{
+				URL:    URL(t, "http://[::1]:80/path"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl ftp.example.com/file.txt`,
+			&curlreq.Parsed{
+				URL:    URL(t, "ftp://ftp.example.com/file.txt"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl --proto-default https ftp.example.com example.com https://example.org`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://example.org"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl api:3000/health`,
+			&curlreq.Parsed{
+				URL:    URL(t, "http://api:3000/health"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl myhost/path`,
+			&curlreq.Parsed{
+				URL:    URL(t, "http://myhost/path"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl example 1.5`,
+			&curlreq.Parsed{
+				URL:    URL(t, "http://1.5"),
 				Method: http.MethodGet,
 				Header: http.Header{},
 			},
@@ -239,7 +299,131 @@ func TestParseExtra(t *testing.T) {
 			if diff := cmp.Diff(got, tt.want, nil); diff != "" {
 				t.Errorf("%s", diff)
 			}
-			_, _ = got.Request()
+			if got.URL.Scheme == "http" {
+				if _, err := got.Request(); err != nil {
+					t.Errorf("Request returned error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestWithDefaultScheme(t *testing.T) {
+	t.Parallel()
+
+	p, err := curlreq.NewParser(curlreq.WithDefaultScheme("HTTPS"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.ParseAll(`curl example.com ftp.example.com http://example.org --url localhost:8443 --next --proto-default http example.net`)
+	if err != nil {
+		t.Fatalf("ParseAll returned error: %v", err)
+	}
+	want := []string{
+		"https://example.com",
+		"https://ftp.example.com",
+		"http://example.org",
+		"https://localhost:8443",
+		"http://example.net",
+	}
+	urls := make([]string, 0, len(got))
+	for _, r := range got {
+		urls = append(urls, r.URL.String())
+	}
+	if diff := cmp.Diff(want, urls); diff != "" {
+		t.Errorf("unexpected URLs (-want +got):\n%s", diff)
+	}
+
+	if _, err := curlreq.NewParser(curlreq.WithDefaultScheme("h t")); err == nil {
+		t.Error("NewParser should return error for invalid scheme")
+	}
+	if _, err := curlreq.Parse(`curl --proto-default :// example.com`); err == nil {
+		t.Error("Parse should return error for invalid --proto-default")
+	}
+}
+
+func TestParseWithHeaderSyntax(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "headers.txt"), []byte("X-A: 1\r\nX-Empty;\n\nmalformed\nUser-Agent:\nX-B: 2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := curlreq.NewParser(curlreq.WithWorkingDirectory(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  *curlreq.Parsed
+	}{
+		{
+			`curl -H 'X-Empty;' -H 'X-A: 1' https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodGet,
+				Header: http.Header{"X-Empty": []string{""}, "X-A": []string{"1"}},
+			},
+		},
+		{
+			`curl -A sloth -H 'user-agent:' -H 'Accept:  ' https://api.example.com`,
+			&curlreq.Parsed{
+				URL:            URL(t, "https://api.example.com"),
+				Method:         http.MethodGet,
+				Header:         http.Header{},
+				RemovedHeaders: []string{"User-Agent", "Accept"},
+			},
+		},
+		{
+			`curl --json '{}' -H 'Content-Type:' https://api.example.com`,
+			&curlreq.Parsed{
+				URL:            URL(t, "https://api.example.com"),
+				Method:         http.MethodPost,
+				Header:         http.Header{"Accept": []string{"application/json"}},
+				Body:           []byte("{}"),
+				RemovedHeaders: []string{"Content-Type"},
+			},
+		},
+		{
+			`curl -H 'X-A:' -H 'X-A: 1' https://api.example.com`,
+			&curlreq.Parsed{
+				URL:            URL(t, "https://api.example.com"),
+				Method:         http.MethodGet,
+				Header:         http.Header{"X-A": []string{"1"}},
+				RemovedHeaders: []string{"X-A"},
+			},
+		},
+		{
+			`curl -H @headers.txt https://api.example.com`,
+			&curlreq.Parsed{
+				URL:            URL(t, "https://api.example.com"),
+				Method:         http.MethodGet,
+				Header:         http.Header{"X-A": []string{"1"}, "X-B": []string{"2"}, "X-Empty": []string{""}},
+				RemovedHeaders: []string{"User-Agent"},
+			},
+		},
+		{
+			`curl -H '' -H ';' -H ':' -H ': 1' -H 'X-A' -H 'X-A;b' -H '@' -H ' ; ' https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+			assertRoundTrip(t, got)
 		})
 	}
 }
@@ -281,13 +465,29 @@ p  -H 'Upgrade-Insecure-Requests: 1' \
 		})
 	}
 
+	t.Run("marshal JSON without URL", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := curlreq.Parse(`curl -H 'A: b'`)
+		if err != nil {
+			t.Fatalf("Parse returned error: %v", err)
+		}
+		got, err := json.Marshal(p)
+		if err != nil {
+			t.Fatalf("json.Marshal returned error: %v", err)
+		}
+		if want := `{"url":"","method":"GET","header":{"A":["b"]}}`; string(got) != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+
 	t.Run("marshal JSON with valid UTF-8 body", func(t *testing.T) {
 		t.Parallel()
 
 		p := &curlreq.Parsed{
 			URL:    URL(t, "https://api.example.com"),
 			Method: http.MethodPost,
-			Header: http.Header{},
+			Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 			Body:   []byte(`{"message":"hello"}`),
 		}
 
@@ -319,7 +519,7 @@ p  -H 'Upgrade-Insecure-Requests: 1' \
 		p := &curlreq.Parsed{
 			URL:    URL(t, "https://api.example.com"),
 			Method: http.MethodPost,
-			Header: http.Header{},
+			Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 			Body:   binaryData,
 		}
 
@@ -399,7 +599,7 @@ func TestParseWithDataFile(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte(`{"key":"value"}`),
 			},
 		},
@@ -412,7 +612,7 @@ func TestParseWithDataFile(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte(`foo=bar&baz=qux`),
 			},
 		},
@@ -425,7 +625,7 @@ func TestParseWithDataFile(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte(`binary content here`),
 			},
 		},
@@ -438,7 +638,7 @@ func TestParseWithDataFile(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte(`{"message":"hello"}`),
 			},
 		},
@@ -451,7 +651,7 @@ func TestParseWithDataFile(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte(`test data`),
 			},
 		},
@@ -464,7 +664,7 @@ func TestParseWithDataFile(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte(`inline content`),
 			},
 		},
@@ -477,7 +677,7 @@ func TestParseWithDataFile(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte(`binary inline`),
 			},
 		},
@@ -490,7 +690,7 @@ func TestParseWithDataFile(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte(`ascii inline`),
 			},
 		},
@@ -507,7 +707,7 @@ func TestParseWithDataFile(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte{0x00, 0x01, 0x02, 0x03, 0xFF, 0xFE, 0xFD, 0x00, 0x00, 0x48, 0x65, 0x6C, 0x6C, 0x6F},
 			},
 		},
@@ -531,6 +731,7 @@ func TestParseWithDataFile(t *testing.T) {
 			if diff := cmp.Diff(tt.want, got); diff != "" {
 				t.Errorf("unexpected result (-want +got):\n%s", diff)
 			}
+			assertRoundTrip(t, got)
 		})
 	}
 
@@ -577,6 +778,77 @@ func TestParseWithDataFile(t *testing.T) {
 	})
 }
 
+func TestParseDataSemantics(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"crlf.txt":     "a=1\r\nb=2\r\n",
+		"nul.bin":      "a\x00b\n",
+		"trailing.txt": "hello world\n\n",
+		"empty.txt":    "",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, err := curlreq.NewParser(curlreq.WithWorkingDirectory(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args string
+		want string
+	}{
+		// -d/--data and --data-ascii strip CR and LF from the file
+		{`-d @crlf.txt`, "a=1b=2"},
+		{`-d @nul.bin`, "a\x00b"},
+		{`-d @trailing.txt`, "hello world"},
+		{`-d @empty.txt`, ""},
+		{`--data @crlf.txt`, "a=1b=2"},
+		{`--data-ascii @crlf.txt`, "a=1b=2"},
+		{`--data-ascii @trailing.txt`, "hello world"},
+		// --data-binary keeps the bytes untouched
+		{`--data-binary @crlf.txt`, "a=1\r\nb=2\r\n"},
+		{`--data-binary @nul.bin`, "a\x00b\n"},
+		{`--data-binary @trailing.txt`, "hello world\n\n"},
+		{`--data-binary @empty.txt`, ""},
+		// --data-raw does not treat @ specially
+		{`--data-raw @crlf.txt`, "@crlf.txt"},
+		{`--data-raw @nul.bin`, "@nul.bin"},
+		// --json keeps the bytes untouched
+		{`--json @crlf.txt`, "a=1\r\nb=2\r\n"},
+		{`--json @trailing.txt`, "hello world\n\n"},
+		// --data-urlencode encodes the whole file content
+		{`--data-urlencode @crlf.txt`, "a%3D1%0D%0Ab%3D2%0D%0A"},
+		{`--data-urlencode name@trailing.txt`, "name=hello+world%0A%0A"},
+		{`--data-urlencode name@nul.bin`, "name=a%00b%0A"},
+		// Multiple data are joined with &
+		{`-d @crlf.txt --data-binary @nul.bin --data-raw @x`, "a=1b=2&a\x00b\n&@x"},
+		// @ alone is not a file
+		{`-d @`, "@"},
+		{`--data-binary @`, "@"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := p.Parse(`curl ` + tt.args + ` https://api.example.com`)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if string(got.Body) != tt.want {
+				t.Errorf("got %q, want %q", got.Body, tt.want)
+			}
+			if got.Method != http.MethodPost {
+				t.Errorf("got %s, want %s", got.Method, http.MethodPost)
+			}
+		})
+	}
+}
+
 func TestWithWorkingDirectory(t *testing.T) {
 	t.Parallel()
 
@@ -680,7 +952,7 @@ func TestParserWithWorkingDirectory(t *testing.T) {
 		want := &curlreq.Parsed{
 			URL:    URL(t, "https://api.example.com"),
 			Method: http.MethodPost,
-			Header: http.Header{},
+			Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 			Body:   content,
 		}
 
@@ -717,7 +989,7 @@ func TestParserWithWorkingDirectory(t *testing.T) {
 		want := &curlreq.Parsed{
 			URL:    URL(t, "https://api.example.com"),
 			Method: http.MethodPost,
-			Header: http.Header{},
+			Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 			Body:   content,
 		}
 
@@ -783,7 +1055,7 @@ func TestParserWithWorkingDirectory(t *testing.T) {
 		want := &curlreq.Parsed{
 			URL:    URL(t, "https://api.example.com"),
 			Method: http.MethodPost,
-			Header: http.Header{},
+			Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 			Body:   []byte("data1&data2"),
 		}
 
@@ -811,7 +1083,7 @@ func TestParseWithDataUrlEncode(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte("hello+world"),
 			},
 		},
@@ -824,7 +1096,7 @@ func TestParseWithDataUrlEncode(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte("=hello+world"),
 			},
 		},
@@ -837,7 +1109,7 @@ func TestParseWithDataUrlEncode(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte("name=hello+world"),
 			},
 		},
@@ -850,7 +1122,7 @@ func TestParseWithDataUrlEncode(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte("key=value%26other%3Ddata"),
 			},
 		},
@@ -863,7 +1135,7 @@ func TestParseWithDataUrlEncode(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte("hello+world"),
 			},
 		},
@@ -876,7 +1148,7 @@ func TestParseWithDataUrlEncode(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte("name=hello+world"),
 			},
 		},
@@ -889,7 +1161,7 @@ func TestParseWithDataUrlEncode(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte("test+data"),
 			},
 		},
@@ -902,7 +1174,7 @@ func TestParseWithDataUrlEncode(t *testing.T) {
 			want: &curlreq.Parsed{
 				URL:    URL(t, "https://api.example.com"),
 				Method: http.MethodPost,
-				Header: http.Header{},
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
 				Body:   []byte("name=John+Doe&city=New+York"),
 			},
 		},
@@ -929,6 +1201,489 @@ func TestParseWithDataUrlEncode(t *testing.T) {
 			if diff := cmp.Diff(tt.want, got); diff != "" {
 				t.Errorf("unexpected result (-want +got):\n%s", diff)
 			}
+			assertRoundTrip(t, got)
 		})
 	}
 }
+
+func TestParseWithURLQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content []byte
+		build   func(path string) string
+		want    *curlreq.Parsed
+	}{
+		{
+			name: "URL without scheme",
+			build: func(path string) string {
+				return `curl --url api.example.com/sloths`
+			},
+			want: &curlreq.Parsed{
+				URL:    URL(t, "http://api.example.com/sloths"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			name: "encoded queries",
+			build: func(path string) string {
+				return `curl --url-query "name=John Doe" --url-query "=a&b" https://api.example.com/?page=2`
+			},
+			want: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/?page=2&name=John+Doe&=a%26b"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			name: "query as is",
+			build: func(path string) string {
+				return `curl --url-query "+a=b c&d" --url https://api.example.com`
+			},
+			want: &curlreq.Parsed{
+				URL:    &url.URL{Scheme: "https", Host: "api.example.com", RawQuery: "a=b c&d"},
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			name:    "query from file",
+			content: []byte("hello world"),
+			build: func(path string) string {
+				return fmt.Sprintf(`curl --url-query "q@%s" https://api.example.com`, path)
+			},
+			want: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com?q=hello+world"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			name: "query with -G data",
+			build: func(path string) string {
+				return `curl -G -d a=1 --url-query b=2 https://api.example.com`
+			},
+			want: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com?b=2&a=1"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var path string
+			if tt.content != nil {
+				dir := t.TempDir()
+				path = filepath.Join(dir, "data.txt")
+				if err := os.WriteFile(path, tt.content, 0o600); err != nil {
+					t.Fatalf("failed to write temp file: %v", err)
+				}
+			}
+
+			got, err := curlreq.Parse(tt.build(path))
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseWithGet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  *curlreq.Parsed
+	}{
+		{
+			`curl -G -d "q=sloth" -d limit=10 https://api.example.com/search`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/search?q=sloth&limit=10"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl --get --data-urlencode "q=galactic sloth" https://api.example.com/search?page=2`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/search?page=2&q=galactic+sloth"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl -I -G -d q=sloth https://api.example.com/search`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/search?q=sloth"),
+				Method: http.MethodHead,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl -X DELETE -G -d id=4 https://api.example.com/sloths`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/sloths?id=4"),
+				Method: http.MethodDelete,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl -G https://api.example.com/search`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/search"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := curlreq.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+			assertRoundTrip(t, got)
+		})
+	}
+
+	t.Run("form options cannot be used with -G", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := curlreq.Parse(`curl -G -F a=b https://api.example.com`); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestParseWithJSON(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+	if err := os.WriteFile(path, []byte(`{"name":"sloth"}`), 0o600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	tests := []struct {
+		input string
+		want  *curlreq.Parsed
+	}{
+		{
+			`curl --json '{"name":"sloth"}' https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{
+					"Accept":       []string{"application/json"},
+					"Content-Type": []string{"application/json"},
+				},
+				Body: []byte(`{"name":"sloth"}`),
+			},
+		},
+		{
+			`curl --json '{"name":' --json='"sloth"}' https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{
+					"Accept":       []string{"application/json"},
+					"Content-Type": []string{"application/json"},
+				},
+				Body: []byte(`{"name":"sloth"}`),
+			},
+		},
+		{
+			fmt.Sprintf(`curl --json @%s -X PUT https://api.example.com`, path),
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPut,
+				Header: http.Header{
+					"Accept":       []string{"application/json"},
+					"Content-Type": []string{"application/json"},
+				},
+				Body: []byte(`{"name":"sloth"}`),
+			},
+		},
+		{
+			`curl --json '{}' -H "Content-Type: application/vnd.api+json" -H "Accept: */*" https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{
+					"Accept":       []string{"*/*"},
+					"Content-Type": []string{"application/vnd.api+json"},
+				},
+				Body: []byte(`{}`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := curlreq.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// assertRoundTrip asserts that parsing the command rendered from p yields p again (except for Parsed.Unknown).
+func assertRoundTrip(t *testing.T, p *curlreq.Parsed) {
+	t.Helper()
+	cmd := p.Command()
+	got, err := curlreq.Parse(cmd)
+	if err != nil {
+		t.Fatalf("Parse(%q) returned error: %v", cmd, err)
+	}
+	// Unknown arguments are not rendered
+	want := *p
+	want.Unknown = nil
+	if diff := cmp.Diff(&want, got); diff != "" {
+		t.Errorf("round trip of %q failed (-want +got):\n%s", cmd, diff)
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("request with GetBody", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := http.NewRequest(http.MethodPut, "https://api.example.com/sloths/4", strings.NewReader(`{"name":"sloth"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Host = "sloths.internal"
+
+		got, err := curlreq.FromRequest(req)
+		if err != nil {
+			t.Fatalf("FromRequest returned error: %v", err)
+		}
+		want := &curlreq.Parsed{
+			URL:    URL(t, "https://api.example.com/sloths/4"),
+			Method: http.MethodPut,
+			Header: http.Header{
+				"Content-Type": []string{"application/json"},
+				"Host":         []string{"sloths.internal"},
+			},
+			Body: []byte(`{"name":"sloth"}`),
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected result (-want +got):\n%s", diff)
+		}
+
+		// Body is not consumed
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != `{"name":"sloth"}` {
+			t.Errorf("body was consumed: %q", b)
+		}
+	})
+
+	t.Run("request without GetBody", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := http.NewRequest(http.MethodPost, "https://api.example.com", io.NopCloser(strings.NewReader("a=b")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cmd, err := curlreq.CommandFromRequest(req)
+		if err != nil {
+			t.Fatalf("CommandFromRequest returned error: %v", err)
+		}
+		if want := `curl --data-binary a=b https://api.example.com`; cmd != want {
+			t.Errorf("got %s, want %s", cmd, want)
+		}
+
+		// Body is restored
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "a=b" {
+			t.Errorf("body was not restored: %q", b)
+		}
+	})
+
+	t.Run("server request", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := http.ReadRequest(bufio.NewReader(strings.NewReader("GET /sloths?page=2 HTTP/1.1\r\nHost: api.example.com\r\nAccept: */*\r\n\r\n")))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := curlreq.FromRequest(req)
+		if err != nil {
+			t.Fatalf("FromRequest returned error: %v", err)
+		}
+		want := &curlreq.Parsed{
+			URL:    URL(t, "http://api.example.com/sloths?page=2"),
+			Method: http.MethodGet,
+			Header: http.Header{
+				"Accept": []string{"*/*"},
+			},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected result (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestParseAll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input     string
+		want      []*curlreq.Parsed
+		wantParse int
+	}{
+		{
+			`curl -H 'X-A: 1' https://api.example.com/a https://api.example.com/b -d c=d`,
+			[]*curlreq.Parsed{
+				{
+					URL:    URL(t, "https://api.example.com/a"),
+					Method: http.MethodPost,
+					Header: http.Header{
+						"Content-Type": []string{"application/x-www-form-urlencoded"},
+						"X-A":          []string{"1"},
+					},
+					Body: []byte("c=d"),
+				},
+				{
+					URL:    URL(t, "https://api.example.com/b"),
+					Method: http.MethodPost,
+					Header: http.Header{
+						"Content-Type": []string{"application/x-www-form-urlencoded"},
+						"X-A":          []string{"1"},
+					},
+					Body: []byte("c=d"),
+				},
+			},
+			1,
+		},
+		{
+			`curl -H 'X-A: 1' https://api.example.com/a --next -X DELETE https://api.example.com/b -: -G -d q=1 https://api.example.com/c https://api.example.com/d?page=2`,
+			[]*curlreq.Parsed{
+				{
+					URL:    URL(t, "https://api.example.com/a"),
+					Method: http.MethodGet,
+					Header: http.Header{"X-A": []string{"1"}},
+				},
+				{
+					URL:    URL(t, "https://api.example.com/b"),
+					Method: http.MethodDelete,
+					Header: http.Header{},
+				},
+				{
+					URL:    URL(t, "https://api.example.com/c?q=1"),
+					Method: http.MethodGet,
+					Header: http.Header{},
+				},
+				{
+					URL:    URL(t, "https://api.example.com/d?page=2&q=1"),
+					Method: http.MethodGet,
+					Header: http.Header{},
+				},
+			},
+			0,
+		},
+		{
+			`curl -H 'X-A: 1' https://api.example.com/a --next`,
+			[]*curlreq.Parsed{
+				{
+					URL:    URL(t, "https://api.example.com/a"),
+					Method: http.MethodGet,
+					Header: http.Header{"X-A": []string{"1"}},
+				},
+			},
+			0,
+		},
+		{
+			`curl -: -H 'X-A: 1' https://api.example.com/a`,
+			[]*curlreq.Parsed{
+				{
+					URL:    URL(t, "https://api.example.com/a"),
+					Method: http.MethodGet,
+					Header: http.Header{"X-A": []string{"1"}},
+				},
+			},
+			0,
+		},
+		{
+			`curl --url https://api.example.com/a --url=https://api.example.com/b`,
+			[]*curlreq.Parsed{
+				{
+					URL:    URL(t, "https://api.example.com/a"),
+					Method: http.MethodGet,
+					Header: http.Header{},
+				},
+				{
+					URL:    URL(t, "https://api.example.com/b"),
+					Method: http.MethodGet,
+					Header: http.Header{},
+				},
+			},
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := curlreq.ParseAll(tt.input)
+			if err != nil {
+				t.Fatalf("ParseAll returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+
+			// Parse returns the last URL of the first group
+			p, err := curlreq.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want[tt.wantParse], p); diff != "" {
+				t.Errorf("unexpected result of Parse (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("headers are not shared between requests", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := curlreq.ParseAll(`curl -H 'X-A: 1' https://api.example.com/a https://api.example.com/b`)
+		if err != nil {
+			t.Fatalf("ParseAll returned error: %v", err)
+		}
+		got[0].Header.Set("X-A", "2")
+		if v := got[1].Header.Get("X-A"); v != "1" {
+			t.Errorf("got %s, want 1", v)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package curlreq
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotCurl is returned when the command is not a curl command.
+	ErrNotCurl = errors.New("not a curl command")
+	// ErrMissingURL is returned when the command has no URL.
+	ErrMissingURL = errors.New("no URL specified")
+	// ErrMissingOptionValue is returned when an option that takes a value has no value.
+	ErrMissingOptionValue = errors.New("option requires a value")
+	// ErrFileRead is returned when a file referenced by the command cannot be read.
+	ErrFileRead = errors.New("failed to read file")
+	// ErrUnknownOption is returned in strict mode when the command has an unknown option.
+	ErrUnknownOption = errors.New("unknown option")
+	// ErrUnsupportedOption is returned in strict mode when the command has an option that changes the request but is not supported.
+	ErrUnsupportedOption = errors.New("unsupported option")
+	// ErrUnexpectedArgument is returned in strict mode when the command has an argument that is neither an option nor a URL.
+	ErrUnexpectedArgument = errors.New("unexpected argument")
+	// ErrFileAccessDenied is returned when file access is denied by FileAccessPolicy.DenyAll.
+	ErrFileAccessDenied = errors.New("file access is denied")
+	// ErrOutsideWorkingDirectory is returned when a file outside the working directory is denied by FileAccessPolicy.RestrictToWorkingDirectory.
+	ErrOutsideWorkingDirectory = errors.New("file is outside the working directory")
+	// ErrFileNotAllowed is returned when a file does not match FileAccessPolicy.Allow.
+	ErrFileNotAllowed = errors.New("file is not allowed")
+	// ErrFileTooLarge is returned when a file is larger than FileAccessPolicy.MaxSize.
+	ErrFileTooLarge = errors.New("file is too large")
+	// ErrStdin is returned when stdin is referenced but cannot be read because it is not configured or already read.
+	ErrStdin = errors.New("stdin cannot be read")
+	// ErrTooManyURLs is returned when a URL glob expands to more URLs than the limit.
+	ErrTooManyURLs = errors.New("too many URLs")
+	// ErrTooManyRedirects is returned by Client when the response redirects more times than Transfer.MaxRedirects.
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+// ParseError is an error with the position of the argument that failed to parse.
+type ParseError struct {
+	// Index is the index of the argument in the command ("curl" is 0). -1 if the error is not related to an argument.
+	Index int
+	// Arg is the raw argument.
+	Arg string
+	// Option is the option being processed when the error occurred.
+	Option string
+	// Offset is the byte offset of the argument in the original command string. -1 if unknown.
+	Offset int
+	// Err is the cause of the error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	b.WriteString("curlreq: ")
+	if e.Option != "" {
+		b.WriteString(e.Option)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Err.Error())
+	if e.Index >= 0 {
+		fmt.Fprintf(&b, " (argument %d: %q)", e.Index, e.Arg)
+	}
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// cmdline is the original curl command used to locate errors.
+type cmdline struct {
+	// args is the arguments of the command including "curl".
+	args []string
+	// offsets is the byte offsets of args in the command string. nil if the command is given as arguments.
+	offsets []int
+}
+
+// newError returns *ParseError for the argument at index in the original command.
+func (c *cmdline) newError(index int, option string, err error) *ParseError {
+	pe := &ParseError{
+		Index:  index,
+		Option: option,
+		Offset: -1,
+		Err:    err,
+	}
+	if index >= 0 && index < len(c.args) {
+		pe.Arg = c.args[index]
+	}
+	if index >= 0 && index < len(c.offsets) {
+		pe.Offset = c.offsets[index]
+	}
+	return pe
+}
+
+// argOffsets returns the byte offsets of the words in the command line, following the same quoting rules as shellwords.
+func argOffsets(line string) []int {
+	var (
+		offsets      []int
+		inWord       bool
+		singleQuoted bool
+		doubleQuoted bool
+		ansiCQuoted  bool
+	)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		quoted := singleQuoted || doubleQuoted || ansiCQuoted
+		if !quoted && strings.IndexByte(" \t\r\n", c) >= 0 {
+			inWord = false
+			continue
+		}
+		if !quoted && strings.IndexByte(";&|<>", c) >= 0 {
+			break
+		}
+		if !inWord {
+			offsets = append(offsets, i)
+			inWord = true
+		}
+		switch {
+		case c == '\\' && !singleQuoted:
+			i++
+		case c == '\'' && ansiCQuoted:
+			ansiCQuoted = false
+		case c == '\'' && !doubleQuoted:
+			singleQuoted = !singleQuoted
+		case c == '"' && !singleQuoted && !ansiCQuoted:
+			doubleQuoted = !doubleQuoted
+		case c == '$' && !quoted && i+1 < len(line) && line[i+1] == '\'':
+			ansiCQuoted = true
+			i++
+		}
+	}
+	return offsets
+}
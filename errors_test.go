@@ -0,0 +1,205 @@
+package curlreq_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/k1LoW/curlreq"
+)
+
+func TestParseError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		cmd        []string
+		wantErr    error
+		wantIndex  int
+		wantArg    string
+		wantOption string
+		wantOffset int
+	}{
+		{
+			name:       "not curl",
+			cmd:        []string{`wget https://example.com`},
+			wantErr:    curlreq.ErrNotCurl,
+			wantIndex:  0,
+			wantArg:    "wget",
+			wantOffset: 0,
+		},
+		{
+			name:       "empty command",
+			cmd:        []string{``},
+			wantErr:    curlreq.ErrNotCurl,
+			wantIndex:  -1,
+			wantOffset: -1,
+		},
+		{
+			name:       "no arguments",
+			cmd:        []string{`curl`},
+			wantErr:    curlreq.ErrMissingURL,
+			wantIndex:  -1,
+			wantOffset: -1,
+		},
+		{
+			name:       "missing option value",
+			cmd:        []string{`curl https://example.com  -H`},
+			wantErr:    curlreq.ErrMissingOptionValue,
+			wantIndex:  2,
+			wantArg:    "-H",
+			wantOption: "-H",
+			wantOffset: 26,
+		},
+		{
+			name:       "missing file",
+			cmd:        []string{`curl -H 'X-A: 1' --data "@does not exist" https://example.com`},
+			wantErr:    curlreq.ErrFileRead,
+			wantIndex:  4,
+			wantArg:    "@does not exist",
+			wantOption: "--data",
+			wantOffset: 24,
+		},
+		{
+			name:       "missing file with inline data option",
+			cmd:        []string{`curl`, `-XPOST`, `--data=@does-not-exist`, `https://example.com`},
+			wantErr:    curlreq.ErrFileRead,
+			wantIndex:  2,
+			wantArg:    "--data=@does-not-exist",
+			wantOption: "--data",
+			wantOffset: -1,
+		},
+		{
+			name:       "missing form file",
+			cmd:        []string{`curl -F file=@does-not-exist https://example.com`},
+			wantErr:    curlreq.ErrFileRead,
+			wantIndex:  2,
+			wantArg:    "file=@does-not-exist",
+			wantOption: "-F",
+			wantOffset: 8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := curlreq.Parse(tt.cmd...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			var pe *curlreq.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("got %T, want *curlreq.ParseError", err)
+			}
+			if pe.Index != tt.wantIndex {
+				t.Errorf("got Index %d, want %d", pe.Index, tt.wantIndex)
+			}
+			if pe.Arg != tt.wantArg {
+				t.Errorf("got Arg %q, want %q", pe.Arg, tt.wantArg)
+			}
+			if pe.Option != tt.wantOption {
+				t.Errorf("got Option %q, want %q", pe.Option, tt.wantOption)
+			}
+			if pe.Offset != tt.wantOffset {
+				t.Errorf("got Offset %d, want %d", pe.Offset, tt.wantOffset)
+			}
+		})
+	}
+
+	t.Run("invalid URL", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := curlreq.Parse(`curl http://%zz`)
+		var pe *curlreq.ParseError
+		if !errors.As(err, &pe) {
+			t.Fatalf("got %T, want *curlreq.ParseError", err)
+		}
+		var ue *url.Error
+		if !errors.As(err, &ue) {
+			t.Errorf("got %v, want *url.Error", err)
+		}
+		if pe.Index != 1 || pe.Offset != 5 {
+			t.Errorf("got Index %d and Offset %d, want 1 and 5", pe.Index, pe.Offset)
+		}
+	})
+
+	t.Run("error message", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := curlreq.Parse(`curl https://example.com -d`)
+		want := `curlreq: -d: option requires a value (argument 2: "-d")`
+		if err == nil || err.Error() != want {
+			t.Errorf("got %v, want %s", err, want)
+		}
+
+		_, err = curlreq.Parse(`curl -F x https://example.com`)
+		want = `curlreq: -F: invalid form field: x (argument 2: "x")`
+		if err == nil || err.Error() != want {
+			t.Errorf("got %v, want %s", err, want)
+		}
+	})
+}
+
+func TestWithStrict(t *testing.T) {
+	t.Parallel()
+
+	p, err := curlreq.NewParser(curlreq.WithStrict())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("unknown options and unexpected arguments", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := p.Parse(`curl --hedaer 'X-A: 1' https://example.com`)
+		if !errors.Is(err, curlreq.ErrUnknownOption) {
+			t.Errorf("got %v, want %v", err, curlreq.ErrUnknownOption)
+		}
+		if !errors.Is(err, curlreq.ErrUnexpectedArgument) {
+			t.Errorf("got %v, want %v", err, curlreq.ErrUnexpectedArgument)
+		}
+		want := "curlreq: --hedaer: unknown option (argument 1: \"--hedaer\")\ncurlreq: unexpected argument (argument 2: \"X-A: 1\")"
+		if err == nil || err.Error() != want {
+			t.Errorf("got %v, want %s", err, want)
+		}
+	})
+
+	t.Run("unsupported options", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := p.Parse(`curl -u a:b --digest --oauth2-bearer token -r 0-99 https://example.com`)
+		if !errors.Is(err, curlreq.ErrUnsupportedOption) {
+			t.Errorf("got %v, want %v", err, curlreq.ErrUnsupportedOption)
+		}
+		want := "curlreq: --digest: unsupported option (argument 3: \"--digest\")\n" +
+			"curlreq: --oauth2-bearer: unsupported option (argument 4: \"--oauth2-bearer\")\n" +
+			"curlreq: -r: unsupported option (argument 6: \"-r\")"
+		if err == nil || err.Error() != want {
+			t.Errorf("got %v, want %s", err, want)
+		}
+		if _, err := p.Parse(`curl --no-digest https://example.com`); err != nil {
+			t.Errorf("Parse returned error: %v", err)
+		}
+	})
+
+	t.Run("unsupported options without strict mode", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := curlreq.Parse(`curl -u a:b --ntlm -e https://example.com/from https://example.com`)
+		if err != nil {
+			t.Fatalf("Parse returned error: %v", err)
+		}
+		if diff := cmp.Diff([]string{"--ntlm", "-e", "https://example.com/from"}, got.Unknown); diff != "" {
+			t.Errorf("unexpected unknown arguments (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("known options", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := p.Parse(`curl -H 'X-A: 1' https://example.com`); err != nil {
+			t.Errorf("Parse returned error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,325 @@
+package curlreq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// BodyFile is a request body read from a file when the request is sent.
+type BodyFile struct {
+	// Path is the path of the file as specified in the command.
+	Path string `json:"path"`
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+	// Open opens the file. It is called every time the body is sent.
+	Open func() (io.ReadCloser, error) `json:"-"`
+}
+
+// fileReader reads files referenced by a curl command. It is created per command so that stdin is read at most once.
+type fileReader struct {
+	fsys   fs.FS
+	wd     string
+	policy FileAccessPolicy
+	// streaming enables lazy bodies for files not smaller than streamThreshold.
+	streaming       bool
+	streamThreshold int64
+	stdin           io.Reader
+	stdinUsed       bool
+}
+
+// newFileReader returns a fileReader for a command.
+func (c *config) newFileReader() *fileReader {
+	return &fileReader{
+		fsys:            c.fsys,
+		wd:              c.wd,
+		policy:          c.policy,
+		streaming:       c.streaming,
+		streamThreshold: c.streamThreshold,
+		stdin:           c.stdin,
+	}
+}
+
+// validateWorkingDirectory checks that the working directory is a directory in the OS or the filesystem set by WithFS.
+func (c *config) validateWorkingDirectory() error {
+	var (
+		fi  fs.FileInfo
+		err error
+	)
+	if c.fsys != nil {
+		fi, err = fs.Stat(c.fsys, c.newFileReader().fsPath("."))
+	} else {
+		fi, err = os.Stat(c.wd)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid base path: %w", err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("base path is not a directory")
+	}
+	return nil
+}
+
+// read reads the file resolved against the working directory. "-" reads stdin.
+func (r *fileReader) read(name string) ([]byte, error) {
+	if name == "-" {
+		return r.readStdin()
+	}
+	b, err := r.readFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrFileRead, name, err)
+	}
+	return b, nil
+}
+
+// bodyFile returns the lazy body of the file when streaming is enabled and the file is large enough.
+// It returns nil when the file should be read into memory.
+func (r *fileReader) bodyFile(name string) (*BodyFile, error) {
+	if !r.streaming || name == "-" {
+		return nil, nil
+	}
+	f, err := r.openFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrFileRead, name, err)
+	}
+	fi, err := f.Stat()
+	_ = f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrFileRead, name, err)
+	}
+	if !fi.Mode().IsRegular() || fi.Size() < r.streamThreshold {
+		return nil, nil
+	}
+	if r.policy.MaxSize > 0 && fi.Size() > r.policy.MaxSize {
+		return nil, fmt.Errorf("%w %s: %w: larger than %d bytes", ErrFileRead, name, ErrFileTooLarge, r.policy.MaxSize)
+	}
+	return &BodyFile{
+		Path: name,
+		Size: fi.Size(),
+		Open: func() (io.ReadCloser, error) {
+			f, err := r.openFile(name)
+			if err != nil {
+				return nil, fmt.Errorf("%w %s: %w", ErrFileRead, name, err)
+			}
+			return f, nil
+		},
+	}, nil
+}
+
+// readFile reads the file following the file access policy.
+func (r *fileReader) readFile(name string) ([]byte, error) {
+	f, err := r.openFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return r.readAll(f)
+}
+
+// openFile opens the file following the file access policy.
+func (r *fileReader) openFile(name string) (fs.File, error) {
+	if r.policy.DenyAll {
+		return nil, ErrFileAccessDenied
+	}
+	if r.fsys != nil {
+		return r.openFS(name)
+	}
+	return r.open(name)
+}
+
+// open opens the file in the OS filesystem after checking the policy.
+func (r *fileReader) open(name string) (fs.File, error) {
+	wd, err := filepath.Abs(r.wd)
+	if err != nil {
+		return nil, err
+	}
+	p := name
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(wd, p)
+	}
+	p = filepath.Clean(p)
+	rel, err := filepath.Rel(wd, p)
+	if err != nil {
+		return nil, err
+	}
+	if r.policy.RestrictToWorkingDirectory {
+		if isOutside(rel) {
+			return nil, ErrOutsideWorkingDirectory
+		}
+		// Reject symlinks that point outside the working directory
+		if real, err := filepath.EvalSymlinks(p); err == nil {
+			realWd, err := filepath.EvalSymlinks(wd)
+			if err != nil {
+				return nil, err
+			}
+			realRel, err := filepath.Rel(realWd, real)
+			if err != nil || isOutside(realRel) {
+				return nil, ErrOutsideWorkingDirectory
+			}
+		}
+	}
+	if !r.policy.allowed(filepath.ToSlash(rel), filepath.ToSlash(p)) {
+		return nil, ErrFileNotAllowed
+	}
+	return os.Open(p)
+}
+
+// openFS opens the file in the filesystem set by WithFS after checking the policy.
+func (r *fileReader) openFS(name string) (fs.File, error) {
+	p := r.fsPath(name)
+	wd := r.fsPath(".")
+	rel := fsRel(wd, p)
+	if r.policy.RestrictToWorkingDirectory {
+		if isOutside(rel) {
+			return nil, ErrOutsideWorkingDirectory
+		}
+		// Reject symlinks that point outside the working directory when the filesystem can read links
+		if lfs, ok := r.fsys.(readLinkFS); ok {
+			real, err := evalFSSymlinks(lfs, p)
+			if err != nil {
+				return nil, err
+			}
+			realWd, err := evalFSSymlinks(lfs, wd)
+			if err != nil {
+				return nil, err
+			}
+			if isOutside(fsRel(realWd, real)) {
+				return nil, ErrOutsideWorkingDirectory
+			}
+		}
+	}
+	if !r.policy.allowed(rel, "/"+p) {
+		return nil, ErrFileNotAllowed
+	}
+	return r.fsys.Open(p)
+}
+
+// readLinkFS is a filesystem that can read symlinks, which is the same as fs.ReadLinkFS of Go 1.25.
+type readLinkFS interface {
+	fs.FS
+	ReadLink(name string) (string, error)
+	Lstat(name string) (fs.FileInfo, error)
+}
+
+// maxSymlinks is the maximum number of symlinks followed to resolve a path.
+const maxSymlinks = 255
+
+// evalFSSymlinks returns the path of name in fsys after resolving symlinks.
+// It returns ErrOutsideWorkingDirectory when a symlink points outside the root of fsys.
+func evalFSSymlinks(fsys readLinkFS, name string) (string, error) {
+	var (
+		resolved string
+		rest     = strings.Split(name, "/")
+		links    int
+	)
+	for len(rest) > 0 {
+		elem := rest[0]
+		rest = rest[1:]
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			if resolved == "" {
+				return "", ErrOutsideWorkingDirectory
+			}
+			resolved = path.Dir(resolved)
+			if resolved == "." {
+				resolved = ""
+			}
+			continue
+		}
+		next := path.Join(resolved, elem)
+		fi, err := fsys.Lstat(next)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				// Opening the file fails later
+				return path.Join(append([]string{next}, rest...)...), nil
+			}
+			return "", err
+		}
+		if fi.Mode()&fs.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		links++
+		if links > maxSymlinks {
+			return "", fmt.Errorf("too many symlinks: %s", name)
+		}
+		target, err := fsys.ReadLink(next)
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(target) || filepath.IsAbs(target) {
+			return "", ErrOutsideWorkingDirectory
+		}
+		rest = append(strings.Split(filepath.ToSlash(target), "/"), rest...)
+	}
+	if resolved == "" {
+		return ".", nil
+	}
+	return resolved, nil
+}
+
+// fsRel returns the path of p relative to the directory wd in the filesystem, or ".." when p is outside wd.
+func fsRel(wd, p string) string {
+	switch {
+	case wd == ".":
+		return p
+	case p == wd:
+		return "."
+	case strings.HasPrefix(p, wd+"/"):
+		return strings.TrimPrefix(p, wd+"/")
+	default:
+		return ".."
+	}
+}
+
+// readAll reads the file up to the maximum size of the policy.
+func (r *fileReader) readAll(f io.Reader) ([]byte, error) {
+	if r.policy.MaxSize == 0 {
+		return io.ReadAll(f)
+	}
+	b, err := io.ReadAll(io.LimitReader(f, r.policy.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > r.policy.MaxSize {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrFileTooLarge, r.policy.MaxSize)
+	}
+	return b, nil
+}
+
+// fsPath returns the path of the file in the filesystem. The working directory is a directory in the filesystem,
+// and an absolute path is relative to the root of the filesystem.
+func (r *fileReader) fsPath(name string) string {
+	p := filepath.ToSlash(name)
+	if !strings.HasPrefix(p, "/") {
+		p = path.Join(filepath.ToSlash(r.wd), p)
+	}
+	p = strings.TrimLeft(path.Clean(p), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+// readStdin reads all of stdin. It returns an error when stdin is not configured or has already been read.
+func (r *fileReader) readStdin() ([]byte, error) {
+	if r.stdin == nil {
+		return nil, fmt.Errorf("%w: no reader is configured", ErrStdin)
+	}
+	if r.stdinUsed {
+		return nil, fmt.Errorf("%w: it is referenced more than once", ErrStdin)
+	}
+	r.stdinUsed = true
+	b, err := io.ReadAll(r.stdin)
+	if err != nil {
+		return nil, fmt.Errorf("%w stdin: %w", ErrFileRead, err)
+	}
+	return b, nil
+}
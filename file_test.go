@@ -0,0 +1,454 @@
+package curlreq_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/k1LoW/curlreq"
+)
+
+func TestWithStdin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		cmd   string
+		stdin string
+		want  *curlreq.Parsed
+	}{
+		{
+			name:  "-d @-",
+			cmd:   `curl -d @- https://api.example.com`,
+			stdin: "a=b",
+			want: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				Body:   []byte("a=b"),
+			},
+		},
+		{
+			name:  "--data-binary @-",
+			cmd:   `curl -d x=y --data-binary @- https://api.example.com`,
+			stdin: `{"a":1}`,
+			want: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				Body:   []byte(`x=y&{"a":1}`),
+			},
+		},
+		{
+			name:  "-T -",
+			cmd:   `curl -T - https://api.example.com/upload/`,
+			stdin: "content",
+			want: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/upload/"),
+				Method: http.MethodPut,
+				Header: http.Header{},
+				Body:   []byte("content"),
+			},
+		},
+		{
+			name:  "-K -",
+			cmd:   `curl -K - -X PATCH`,
+			stdin: "url = https://api.example.com\nheader = \"X-A: 1\"\n",
+			want: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPatch,
+				Header: http.Header{"X-A": []string{"1"}},
+			},
+		},
+		{
+			name:  "-F file=@-",
+			cmd:   `curl -F 'file=@-;filename=a.txt' https://api.example.com`,
+			stdin: "content",
+			want: &curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{},
+				Form: []*curlreq.FormPart{
+					{Name: "file", Value: []byte("content"), Filename: "a.txt", ContentType: "application/octet-stream"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := curlreq.NewParser(curlreq.WithStdin(strings.NewReader(tt.stdin)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Parse(tt.cmd)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("stdin is referenced twice", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := curlreq.NewParser(curlreq.WithStdin(strings.NewReader("a=b")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = p.Parse(`curl -d @- -H 'X-A: 1' -d @- https://api.example.com`)
+		if !errors.Is(err, curlreq.ErrStdin) {
+			t.Fatalf("got %v, want %v", err, curlreq.ErrStdin)
+		}
+		var pe *curlreq.ParseError
+		if !errors.As(err, &pe) || pe.Index != 6 {
+			t.Errorf("got %#v, want error at argument 6", pe)
+		}
+		if want := `curlreq: -d: stdin cannot be read: it is referenced more than once (argument 6: "@-")`; err.Error() != want {
+			t.Errorf("got %q, want %q", err.Error(), want)
+		}
+	})
+
+	t.Run("no reader", func(t *testing.T) {
+		t.Parallel()
+
+		for _, cmd := range []string{
+			`curl -d @- https://api.example.com`,
+			`curl -T - https://api.example.com`,
+			`curl -K - https://api.example.com`,
+			`curl -F file=@- https://api.example.com`,
+		} {
+			if _, err := curlreq.Parse(cmd); !errors.Is(err, curlreq.ErrStdin) {
+				t.Errorf("%s: got %v, want %v", cmd, err, curlreq.ErrStdin)
+			}
+		}
+	})
+}
+
+func TestParseWithUploadFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "sloth.txt"), []byte("sloth"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := curlreq.NewParser(curlreq.WithWorkingDirectory(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		cmd  string
+		want *curlreq.Parsed
+	}{
+		{
+			`curl -T sloth.txt https://api.example.com/upload/`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/upload/sloth.txt"),
+				Method: http.MethodPut,
+				Header: http.Header{},
+				Body:   []byte("sloth"),
+			},
+		},
+		{
+			`curl --upload-file sloth.txt https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/sloth.txt"),
+				Method: http.MethodPut,
+				Header: http.Header{},
+				Body:   []byte("sloth"),
+			},
+		},
+		{
+			`curl -X POST -T sloth.txt https://api.example.com/upload`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/upload"),
+				Method: http.MethodPost,
+				Header: http.Header{},
+				Body:   []byte("sloth"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := p.Parse(tt.cmd)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"fixtures/data.txt":     {Data: []byte("a=b")},
+		"fixtures/upload.txt":   {Data: []byte("upload")},
+		"fixtures/headers.txt":  {Data: []byte("X-A: 1\r\nX-B: 2\n")},
+		"fixtures/config.txt":   {Data: []byte("header = \"X-C: 3\"\nurl = https://api.example.com/\n")},
+		"fixtures/sloth.json":   {Data: []byte(`{"name":"sloth"}`)},
+		"other/secret.txt":      {Data: []byte("secret")},
+		"fixtures/sub/data.txt": {Data: []byte("sub")},
+	}
+	p, err := curlreq.NewParser(curlreq.WithFS(fsys), curlreq.WithWorkingDirectory("fixtures"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		cmd  string
+		want *curlreq.Parsed
+	}{
+		{
+			`curl -d @data.txt --data-binary @/fixtures/sub/data.txt https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				Body:   []byte("a=b&sub"),
+			},
+		},
+		{
+			`curl -K config.txt -H @headers.txt -T upload.txt`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com/upload.txt"),
+				Method: http.MethodPut,
+				Header: http.Header{"X-A": []string{"1"}, "X-B": []string{"2"}, "X-C": []string{"3"}},
+				Body:   []byte("upload"),
+			},
+		},
+		{
+			`curl -F file=@sloth.json https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{},
+				Form: []*curlreq.FormPart{
+					{Name: "file", Value: []byte(`{"name":"sloth"}`), Filename: "sloth.json", ContentType: "application/octet-stream"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := p.Parse(tt.cmd)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("files outside the filesystem", func(t *testing.T) {
+		t.Parallel()
+
+		for _, cmd := range []string{
+			`curl -d @../../other/secret.txt https://api.example.com`,
+			`curl -d @does-not-exist.txt https://api.example.com`,
+		} {
+			if _, err := p.Parse(cmd); !errors.Is(err, curlreq.ErrFileRead) {
+				t.Errorf("%s: got %v, want %v", cmd, err, curlreq.ErrFileRead)
+			}
+		}
+	})
+
+	t.Run("working directory must exist in the filesystem", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := curlreq.NewParser(curlreq.WithWorkingDirectory("does-not-exist"), curlreq.WithFS(fsys)); err == nil {
+			t.Error("NewParser should return error for missing working directory")
+		}
+	})
+}
+
+func TestWithStreamingBody(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	content := strings.Repeat("sloth", 100)
+	if err := os.WriteFile(filepath.Join(dir, "dump.bin"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := curlreq.NewParser(curlreq.WithWorkingDirectory(dir), curlreq.WithStreamingBody(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ignoreOpen := cmpopts.IgnoreFields(curlreq.BodyFile{}, "Open")
+
+	tests := []struct {
+		cmd  string
+		want *curlreq.Parsed
+	}{
+		{
+			`curl --data-binary @dump.bin https://api.example.com`,
+			&curlreq.Parsed{
+				URL:      URL(t, "https://api.example.com"),
+				Method:   http.MethodPost,
+				Header:   http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				BodyFile: &curlreq.BodyFile{Path: "dump.bin", Size: int64(len(content))},
+			},
+		},
+		{
+			`curl -T dump.bin https://api.example.com/upload/`,
+			&curlreq.Parsed{
+				URL:      URL(t, "https://api.example.com/upload/dump.bin"),
+				Method:   http.MethodPut,
+				Header:   http.Header{},
+				BodyFile: &curlreq.BodyFile{Path: "dump.bin", Size: int64(len(content))},
+			},
+		},
+		{
+			`curl --data-binary @dump.bin -d a=b https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				Body:   []byte(content + "&a=b"),
+			},
+		},
+		{
+			`curl -d a=b --data-binary @dump.bin https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				Body:   []byte("a=b&" + content),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := p.Parse(tt.cmd)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got, ignoreOpen); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("small files are read into memory", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := curlreq.NewParser(curlreq.WithWorkingDirectory(dir), curlreq.WithStreamingBody(int64(len(content)+1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := p.Parse(`curl --data-binary @dump.bin https://api.example.com`)
+		if err != nil {
+			t.Fatalf("Parse returned error: %v", err)
+		}
+		if got.BodyFile != nil || string(got.Body) != content {
+			t.Errorf("got BodyFile %v and Body %q, want Body only", got.BodyFile, got.Body)
+		}
+	})
+
+	t.Run("request", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := p.Parse(`curl --data-binary @dump.bin https://api.example.com`)
+		if err != nil {
+			t.Fatalf("Parse returned error: %v", err)
+		}
+		req, err := got.Request()
+		if err != nil {
+			t.Fatalf("Request returned error: %v", err)
+		}
+		if req.ContentLength != int64(len(content)) {
+			t.Errorf("got ContentLength %d, want %d", req.ContentLength, len(content))
+		}
+		for _, open := range []func() (io.ReadCloser, error){
+			func() (io.ReadCloser, error) { return req.Body, nil },
+			req.GetBody,
+		} {
+			rc, err := open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = rc.Close()
+			if string(b) != content {
+				t.Errorf("got body %q, want %q", b, content)
+			}
+		}
+	})
+
+	t.Run("body file is closed on invalid request", func(t *testing.T) {
+		t.Parallel()
+
+		closed := false
+		in := &curlreq.Parsed{
+			URL:    URL(t, "https://api.example.com"),
+			Method: "BAD METHOD",
+			Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+			BodyFile: &curlreq.BodyFile{
+				Path: "dump.bin",
+				Open: func() (io.ReadCloser, error) {
+					return &closeRecorder{Reader: strings.NewReader(content), closed: &closed}, nil
+				},
+			},
+		}
+		if _, err := in.Request(); err == nil {
+			t.Fatal("Request should return error for invalid method")
+		}
+		if !closed {
+			t.Error("body file should be closed")
+		}
+	})
+
+	t.Run("command and JSON", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := p.Parse(`curl -T dump.bin https://api.example.com/dump`)
+		if err != nil {
+			t.Fatalf("Parse returned error: %v", err)
+		}
+		if want := `curl -X PUT --data-binary @dump.bin https://api.example.com/dump`; got.Command() != want {
+			t.Errorf("got %s, want %s", got.Command(), want)
+		}
+		b, err := json.Marshal(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `"body_file":{"path":"dump.bin","size":500}`; !strings.Contains(string(b), want) {
+			t.Errorf("got %s, want to contain %s", b, want)
+		}
+	})
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed *bool
+}
+
+func (r *closeRecorder) Close() error {
+	*r.closed = true
+	return nil
+}
@@ -0,0 +1,240 @@
+package curlreq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+)
+
+// FormPart is a part of a multipart/form-data body specified by -F/--form or --form-string.
+type FormPart struct {
+	Name        string
+	Value       []byte
+	Filename    string
+	ContentType string
+	Header      http.Header
+}
+
+// formContentTypes is the file extension table curl uses to guess the Content-Type of uploaded files.
+var formContentTypes = map[string]string{
+	".gif":  "image/gif",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".svg":  "image/svg+xml",
+	".txt":  "text/plain",
+	".htm":  "text/html",
+	".html": "text/html",
+	".pdf":  "application/pdf",
+	".xml":  "application/xml",
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func (f *FormPart) MarshalJSON() ([]byte, error) {
+	value, encoding := encodeBody(f.Value)
+	s := struct {
+		Name          string      `json:"name"`
+		Value         string      `json:"value,omitempty"`
+		ValueEncoding string      `json:"value_encoding,omitempty"`
+		Filename      string      `json:"filename,omitempty"`
+		ContentType   string      `json:"content_type,omitempty"`
+		Header        http.Header `json:"header,omitempty"`
+	}{
+		Name:          f.Name,
+		Value:         value,
+		ValueEncoding: encoding,
+		Filename:      f.Filename,
+		ContentType:   f.ContentType,
+		Header:        f.Header,
+	}
+	return json.Marshal(s)
+}
+
+// parseFormPart parses the value of -F/--form (or --form-string when literal is true).
+func parseFormPart(a string, literal bool, files *fileReader) (*FormPart, error) {
+	i := strings.Index(a, "=")
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid form field: %s", a)
+	}
+	part := &FormPart{
+		Name: a[:i],
+	}
+	value := a[i+1:]
+	if literal {
+		part.Value = []byte(value)
+		return part, nil
+	}
+
+	params := splitFormParams(value)
+	content := params[0]
+	switch {
+	case strings.HasPrefix(content, "@"):
+		// Format: name=@file (upload file)
+		path := unquoteFormWord(content[1:])
+		b, err := files.read(path)
+		if err != nil {
+			return nil, err
+		}
+		part.Value = b
+		part.Filename = filepath.Base(path)
+		part.ContentType = guessFormContentType(path)
+	case strings.HasPrefix(content, "<"):
+		// Format: name=<file (use file content as value)
+		path := unquoteFormWord(content[1:])
+		b, err := files.read(path)
+		if err != nil {
+			return nil, err
+		}
+		part.Value = b
+	default:
+		part.Value = []byte(unquoteFormWord(content))
+	}
+
+	lastKey := ""
+	for _, param := range params[1:] {
+		k, v, ok := strings.Cut(strings.TrimLeft(param, " "), "=")
+		if !ok {
+			k = ""
+		}
+		switch k {
+		case "type":
+			part.ContentType = unquoteFormWord(v)
+		case "filename":
+			part.Filename = unquoteFormWord(v)
+		case "headers":
+			if err := parseFormHeaders(part, unquoteFormWord(v), files); err != nil {
+				return nil, err
+			}
+		case "encoder":
+			// Content-Transfer-Encoding is not supported by net/http clients, so it is ignored.
+		default:
+			// Parameters of the content type (e.g. type=text/plain;charset=utf-8)
+			if lastKey == "type" {
+				part.ContentType += ";" + param
+				continue
+			}
+		}
+		lastKey = k
+	}
+
+	return part, nil
+}
+
+// parseFormHeaders adds the value of ;headers= to the part. @file reads one header per line.
+func parseFormHeaders(part *FormPart, v string, files *fileReader) error {
+	if part.Header == nil {
+		part.Header = http.Header{}
+	}
+	if !strings.HasPrefix(v, "@") {
+		k, hv, ok := parseField(v)
+		if !ok {
+			return fmt.Errorf("invalid form part header: %s", v)
+		}
+		part.Header.Add(k, hv)
+		return nil
+	}
+	b, err := files.read(v[1:])
+	if err != nil {
+		return err
+	}
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		k, hv, ok := parseField(line)
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		part.Header.Add(k, hv)
+	}
+	return s.Err()
+}
+
+// buildMultipartBody writes the parts as multipart/form-data and returns the body and its Content-Type.
+func buildMultipartBody(parts []*FormPart) ([]byte, string, error) {
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	for _, part := range parts {
+		h := textproto.MIMEHeader{}
+		disposition := fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(part.Name))
+		if part.Filename != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, quoteEscaper.Replace(part.Filename))
+		}
+		h.Set("Content-Disposition", disposition)
+		if part.ContentType != "" {
+			h.Set("Content-Type", part.ContentType)
+		}
+		for k, vs := range part.Header {
+			for _, v := range vs {
+				h.Add(k, v)
+			}
+		}
+		pw, err := w.CreatePart(h)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := pw.Write(part.Value); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+// splitFormParams splits the value of -F on semicolons that are not enclosed in double quotes.
+func splitFormParams(s string) []string {
+	var (
+		params []string
+		b      strings.Builder
+		quoted bool
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && quoted && i+1 < len(s):
+			b.WriteByte(c)
+			b.WriteByte(s[i+1])
+			i++
+		case c == '"':
+			quoted = !quoted
+			b.WriteByte(c)
+		case c == ';' && !quoted:
+			params = append(params, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return append(params, b.String())
+}
+
+// unquoteFormWord removes the double quotes and backslash escapes around a word in the value of -F.
+func unquoteFormWord(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func guessFormContentType(path string) string {
+	if ct, ok := formContentTypes[strings.ToLower(filepath.Ext(path))]; ok {
+		return ct
+	}
+	return "application/octet-stream"
+}
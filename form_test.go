@@ -0,0 +1,240 @@
+package curlreq_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/k1LoW/curlreq"
+)
+
+func TestParseWithForm(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "image.png"), []byte("PNG"), 0o600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data.bin"), []byte{0x00, 0x01}, 0o600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "message.txt"), []byte("hello"), 0o600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "headers.txt"), []byte("# comment\nX-A: 1\r\nX-B: 2\n"), 0o600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	tests := []struct {
+		input string
+		want  *curlreq.Parsed
+	}{
+		{
+			`curl -F name=John https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{},
+				Form: []*curlreq.FormPart{
+					{Name: "name", Value: []byte("John")},
+				},
+			},
+		},
+		{
+			`curl --form "name=John;type=text/plain;charset=utf-8" --form age=20 https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{},
+				Form: []*curlreq.FormPart{
+					{Name: "name", Value: []byte("John"), ContentType: "text/plain;charset=utf-8"},
+					{Name: "age", Value: []byte("20")},
+				},
+			},
+		},
+		{
+			`curl --form-string "name=@John;type=text/plain" https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{},
+				Form: []*curlreq.FormPart{
+					{Name: "name", Value: []byte("@John;type=text/plain")},
+				},
+			},
+		},
+		{
+			`curl -F file=@image.png https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{},
+				Form: []*curlreq.FormPart{
+					{Name: "file", Value: []byte("PNG"), Filename: "image.png", ContentType: "image/png"},
+				},
+			},
+		},
+		{
+			`curl -F 'file=@data.bin;filename="a;b.bin"' https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{},
+				Form: []*curlreq.FormPart{
+					{Name: "file", Value: []byte{0x00, 0x01}, Filename: "a;b.bin", ContentType: "application/octet-stream"},
+				},
+			},
+		},
+		{
+			`curl -F "msg=<message.txt;headers=X-Custom: yes" https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{},
+				Form: []*curlreq.FormPart{
+					{Name: "msg", Value: []byte("hello"), Header: http.Header{"X-Custom": []string{"yes"}}},
+				},
+			},
+		},
+		{
+			`curl -F "msg=hi;headers=@headers.txt" -X PUT https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPut,
+				Header: http.Header{},
+				Form: []*curlreq.FormPart{
+					{Name: "msg", Value: []byte("hi"), Header: http.Header{"X-A": []string{"1"}, "X-B": []string{"2"}}},
+				},
+			},
+		},
+	}
+
+	p, err := curlreq.NewParser(curlreq.WithWorkingDirectory(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+			assertRoundTrip(t, got)
+		})
+	}
+
+	errTests := []string{
+		`curl -F novalue https://api.example.com`,
+		`curl -F file=@does-not-exist https://api.example.com`,
+		`curl -F "a=b;headers=invalid" https://api.example.com`,
+		`curl -F a=b -d c=d https://api.example.com`,
+	}
+	for _, input := range errTests {
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := p.Parse(input); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestFormRequest(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "upload.txt")
+	if err := os.WriteFile(path, []byte("file content"), 0o600); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		input     string
+		wantType  string
+		wantParts []*curlreq.FormPart
+	}{
+		{
+			name:     "generated boundary",
+			input:    fmt.Sprintf(`curl -F name=John -F "file=@%s;type=text/x-custom" -F 'quoted"x=y' https://api.example.com`, path),
+			wantType: "multipart/form-data",
+			wantParts: []*curlreq.FormPart{
+				{Name: "name", Value: []byte("John")},
+				{Name: "file", Value: []byte("file content"), Filename: "upload.txt", ContentType: "text/x-custom"},
+				{Name: `quoted"x`, Value: []byte("y")},
+			},
+		},
+		{
+			name:     "user-specified multipart type",
+			input:    `curl -H "Content-Type: multipart/mixed" -F name=John https://api.example.com`,
+			wantType: "multipart/mixed",
+			wantParts: []*curlreq.FormPart{
+				{Name: "name", Value: []byte("John")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := curlreq.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			req, err := parsed.Request()
+			if err != nil {
+				t.Fatalf("Request() returned error: %v", err)
+			}
+			if parsed.Header.Get("Content-Type") == "multipart/form-data" {
+				t.Error("Request() should not modify Parsed.Header")
+			}
+
+			mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+			if err != nil {
+				t.Fatalf("invalid Content-Type: %v", err)
+			}
+			if mediaType != tt.wantType {
+				t.Errorf("got %s, want %s", mediaType, tt.wantType)
+			}
+
+			got := []*curlreq.FormPart{}
+			r := multipart.NewReader(req.Body, params["boundary"])
+			for {
+				part, err := r.NextPart()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("failed to read part: %v", err)
+				}
+				b, err := io.ReadAll(part)
+				if err != nil {
+					t.Fatalf("failed to read part: %v", err)
+				}
+				got = append(got, &curlreq.FormPart{
+					Name:        part.FormName(),
+					Value:       b,
+					Filename:    part.FileName(),
+					ContentType: part.Header.Get("Content-Type"),
+				})
+			}
+			if diff := cmp.Diff(tt.wantParts, got); diff != "" {
+				t.Errorf("unexpected parts (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
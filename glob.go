@@ -0,0 +1,185 @@
+package curlreq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultGlobLimit is the default maximum number of URLs expanded from a URL glob.
+const defaultGlobLimit = 10000
+
+// globSegment is a part of a URL glob. A literal segment has exactly one value.
+type globSegment struct {
+	values []string
+	glob   bool
+}
+
+// globExpansion is a URL expanded from a URL glob and the values of each glob used for it.
+type globExpansion struct {
+	url    string
+	values []string
+}
+
+// expandGlob expands URL globbing such as [1-100], [001-100], [a-z], [0-100:10] and {a,b,c}
+// as curl does. It returns an error when the number of URLs exceeds limit.
+func expandGlob(s string, limit int) ([]globExpansion, error) {
+	segments, err := parseGlob(s, limit)
+	if err != nil {
+		return nil, err
+	}
+	total := 1
+	for _, seg := range segments {
+		total *= len(seg.values)
+		if total > limit {
+			return nil, fmt.Errorf("%w: %s expands to more than %d URLs", ErrTooManyURLs, s, limit)
+		}
+	}
+
+	expansions := []globExpansion{{}}
+	for _, seg := range segments {
+		next := make([]globExpansion, 0, len(expansions)*len(seg.values))
+		for _, e := range expansions {
+			for _, v := range seg.values {
+				values := e.values
+				if seg.glob {
+					values = append(append([]string{}, e.values...), v)
+				}
+				next = append(next, globExpansion{url: e.url + v, values: values})
+			}
+		}
+		expansions = next
+	}
+	return expansions, nil
+}
+
+// parseGlob splits the URL glob s into segments.
+func parseGlob(s string, limit int) ([]globSegment, error) {
+	var (
+		segments []globSegment
+		literal  strings.Builder
+	)
+	flush := func() {
+		if literal.Len() > 0 {
+			segments = append(segments, globSegment{values: []string{literal.String()}})
+			literal.Reset()
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("[]{}", s[i+1]) >= 0:
+			literal.WriteByte(s[i+1])
+			i++
+		case c == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unmatched brace in %s", s)
+			}
+			flush()
+			segments = append(segments, globSegment{values: strings.Split(s[i+1:i+end], ","), glob: true})
+			i += end
+		case c == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unmatched bracket in %s", s)
+			}
+			body := s[i+1 : i+end]
+			if strings.Contains(body, ":") && !strings.Contains(body, "-") {
+				// IPv6 address such as [::1]
+				literal.WriteString(s[i : i+end+1])
+				i += end
+				continue
+			}
+			values, err := expandRange(body, limit)
+			if err != nil {
+				return nil, fmt.Errorf("%w in %s", err, s)
+			}
+			flush()
+			segments = append(segments, globSegment{values: values, glob: true})
+			i += end
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	flush()
+	return segments, nil
+}
+
+// expandRange expands the range glob such as 1-100, 001-100, a-z or 0-100:10.
+func expandRange(body string, limit int) ([]string, error) {
+	r, stepStr, hasStep := strings.Cut(body, ":")
+	start, end, ok := strings.Cut(r, "-")
+	if !ok || start == "" || end == "" {
+		return nil, fmt.Errorf("bad range [%s]", body)
+	}
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepStr)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("bad range step [%s]", body)
+		}
+		step = n
+	}
+
+	var values []string
+	if isLetter(start) && isLetter(end) {
+		// Letter range: [a-z]
+		if start[0] > end[0] {
+			return nil, fmt.Errorf("bad range [%s]", body)
+		}
+		for c := int(start[0]); c <= int(end[0]); c += step {
+			values = append(values, string(rune(c)))
+		}
+		return values, nil
+	}
+
+	from, err := strconv.ParseUint(start, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("bad range [%s]", body)
+	}
+	to, err := strconv.ParseUint(end, 10, 64)
+	if err != nil || from > to {
+		return nil, fmt.Errorf("bad range [%s]", body)
+	}
+	if (to-from)/uint64(step) >= uint64(limit) {
+		return nil, fmt.Errorf("%w: [%s] expands to more than %d URLs", ErrTooManyURLs, body, limit)
+	}
+	width := 0
+	if len(start) > 1 && start[0] == '0' {
+		// Zero-padded range: [001-100]
+		width = len(start)
+	}
+	// Count the values instead of comparing with to, which would overflow near math.MaxUint64
+	for i := range (to-from)/uint64(step) + 1 {
+		values = append(values, fmt.Sprintf("%0*d", width, from+i*uint64(step)))
+	}
+	return values, nil
+}
+
+// substituteGlob replaces #1, #2, ... in s with the values of globs.
+func substituteGlob(s string, values []string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '#' {
+			b.WriteByte(s[i])
+			continue
+		}
+		j := i + 1
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		n, err := strconv.Atoi(s[i+1 : j])
+		if err != nil || n < 1 || n > len(values) {
+			b.WriteByte(s[i])
+			continue
+		}
+		b.WriteString(values[n-1])
+		i = j - 1
+	}
+	return b.String()
+}
+
+func isLetter(s string) bool {
+	return len(s) == 1 && (s[0] >= 'a' && s[0] <= 'z' || s[0] >= 'A' && s[0] <= 'Z')
+}
@@ -0,0 +1,187 @@
+package curlreq_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/k1LoW/curlreq"
+)
+
+func TestParseAllWithURLGlobbing(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{
+			`curl https://api.example.com/items/[1-3]`,
+			[]string{
+				"https://api.example.com/items/1",
+				"https://api.example.com/items/2",
+				"https://api.example.com/items/3",
+			},
+		},
+		{
+			`curl 'https://api.example.com/items/[1-10:4]'`,
+			[]string{
+				"https://api.example.com/items/1",
+				"https://api.example.com/items/5",
+				"https://api.example.com/items/9",
+			},
+		},
+		{
+			`curl 'https://api.example.com/items/[098-101]'`,
+			[]string{
+				"https://api.example.com/items/098",
+				"https://api.example.com/items/099",
+				"https://api.example.com/items/100",
+				"https://api.example.com/items/101",
+			},
+		},
+		{
+			`curl 'https://api.example.com/[a-c].txt'`,
+			[]string{
+				"https://api.example.com/a.txt",
+				"https://api.example.com/b.txt",
+				"https://api.example.com/c.txt",
+			},
+		},
+		{
+			`curl 'https://{eu,us}.api.example.com/v[1-2]'`,
+			[]string{
+				"https://eu.api.example.com/v1",
+				"https://eu.api.example.com/v2",
+				"https://us.api.example.com/v1",
+				"https://us.api.example.com/v2",
+			},
+		},
+		{
+			`curl 'http://[::1]:8080/[1-2]'`,
+			[]string{
+				"http://[::1]:8080/1",
+				"http://[::1]:8080/2",
+			},
+		},
+		{
+			`curl 'https://api.example.com/[18446744073709551614-18446744073709551615]'`,
+			[]string{
+				"https://api.example.com/18446744073709551614",
+				"https://api.example.com/18446744073709551615",
+			},
+		},
+		{
+			`curl 'https://api.example.com/[18446744073709551610-18446744073709551615:4]'`,
+			[]string{
+				"https://api.example.com/18446744073709551610",
+				"https://api.example.com/18446744073709551614",
+			},
+		},
+		{
+			`curl 'https://api.example.com/\[1-2\]'`,
+			[]string{
+				"https://api.example.com/[1-2]",
+			},
+		},
+		{
+			`curl -g 'https://api.example.com/items/[1-3]'`,
+			[]string{
+				"https://api.example.com/items/[1-3]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := curlreq.ParseAll(tt.input)
+			if err != nil {
+				t.Fatalf("ParseAll returned error: %v", err)
+			}
+			urls := make([]string, 0, len(got))
+			for _, p := range got {
+				urls = append(urls, p.URL.String())
+			}
+			if diff := cmp.Diff(tt.want, urls); diff != "" {
+				t.Errorf("unexpected URLs (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("output names", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := curlreq.ParseAll(`curl -H 'X-A: 1' 'https://{eu,us}.api.example.com/[1-2]' -o '#1_#2.json' https://api.example.com/other`)
+		if err != nil {
+			t.Fatalf("ParseAll returned error: %v", err)
+		}
+		want := []*curlreq.Parsed{
+			{URL: URL(t, "https://eu.api.example.com/1"), Method: http.MethodGet, Header: http.Header{"X-A": []string{"1"}}, Output: "eu_1.json"},
+			{URL: URL(t, "https://eu.api.example.com/2"), Method: http.MethodGet, Header: http.Header{"X-A": []string{"1"}}, Output: "eu_2.json"},
+			{URL: URL(t, "https://us.api.example.com/1"), Method: http.MethodGet, Header: http.Header{"X-A": []string{"1"}}, Output: "us_1.json"},
+			{URL: URL(t, "https://us.api.example.com/2"), Method: http.MethodGet, Header: http.Header{"X-A": []string{"1"}}, Output: "us_2.json"},
+			{URL: URL(t, "https://api.example.com/other"), Method: http.MethodGet, Header: http.Header{"X-A": []string{"1"}}},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected result (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("glob limit", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := curlreq.NewParser(curlreq.WithGlobLimit(5))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.ParseAll(`curl 'https://api.example.com/[1-5]'`); err != nil {
+			t.Errorf("ParseAll returned error: %v", err)
+		}
+		_, err = p.ParseAll(`curl 'https://api.example.com/[1-3]/{a,b}'`)
+		if !errors.Is(err, curlreq.ErrTooManyURLs) {
+			t.Errorf("got %v, want %v", err, curlreq.ErrTooManyURLs)
+		}
+		_, err = curlreq.ParseAll(`curl 'https://api.example.com/[1-100000000000]'`)
+		if !errors.Is(err, curlreq.ErrTooManyURLs) {
+			t.Errorf("got %v, want %v", err, curlreq.ErrTooManyURLs)
+		}
+		if _, err := curlreq.NewParser(curlreq.WithGlobLimit(0)); err == nil {
+			t.Error("NewParser should return error for zero glob limit")
+		}
+	})
+
+	t.Run("bad globs", func(t *testing.T) {
+		t.Parallel()
+
+		for _, in := range []string{
+			`curl 'https://api.example.com/[1-'`,
+			`curl 'https://api.example.com/{a,b'`,
+			`curl 'https://api.example.com/[3-1]'`,
+			`curl 'https://api.example.com/[1-3:0]'`,
+		} {
+			_, err := curlreq.ParseAll(in)
+			var pe *curlreq.ParseError
+			if !errors.As(err, &pe) {
+				t.Errorf("%s: got %v, want *curlreq.ParseError", in, err)
+			}
+		}
+	})
+}
+
+func TestCommandWithGlobChars(t *testing.T) {
+	t.Parallel()
+
+	in := &curlreq.Parsed{
+		URL:    URL(t, "http://[::1]:8080/items/[1-3]?q={x}"),
+		Method: http.MethodGet,
+		Header: http.Header{},
+		Output: "out #1.json",
+	}
+	want := `curl -o 'out #1.json' -g 'http://[::1]:8080/items/[1-3]?q={x}'`
+	if got := in.Command(); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	assertRoundTrip(t, in)
+}
@@ -0,0 +1,111 @@
+package curlreq
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// HeaderField is a header as written in the command.
+type HeaderField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// RequestOption is an option for (*Parsed).Request.
+type RequestOption func(*requestConfig)
+
+type requestConfig struct {
+	rawHeaderNames bool
+}
+
+// WithRawHeaders records the headers in Parsed.RawHeaders in the order and case of the command.
+func WithRawHeaders() Option {
+	return func(c *config) error {
+		c.rawHeaders = true
+		return nil
+	}
+}
+
+// WithRawHeaderNames makes (*Parsed).Request use the header names in Parsed.RawHeaders as is instead of canonical names.
+// Note that net/http sends headers sorted by name, so the order is not preserved.
+func WithRawHeaderNames() RequestOption {
+	return func(c *requestConfig) {
+		c.rawHeaderNames = true
+	}
+}
+
+// headerFields returns the headers in order. RawHeaders is used when it is consistent with Header,
+// otherwise the headers are sorted by name.
+func (p *Parsed) headerFields() []HeaderField {
+	if p.rawHeadersConsistent() {
+		return p.RawHeaders
+	}
+	keys := slices.Sorted(maps.Keys(p.Header))
+	var fields []HeaderField
+	for _, k := range keys {
+		for _, v := range p.Header[k] {
+			fields = append(fields, HeaderField{Name: k, Value: v})
+		}
+	}
+	return fields
+}
+
+// rawHeadersConsistent reports whether RawHeaders has the same headers as Header.
+// It is false when Header has been modified after parsing.
+func (p *Parsed) rawHeadersConsistent() bool {
+	if p.RawHeaders == nil {
+		return false
+	}
+	h := http.Header{}
+	for _, f := range p.RawHeaders {
+		h.Add(f.Name, f.Value)
+	}
+	return maps.EqualFunc(h, p.Header, slices.Equal)
+}
+
+// rawHeaderNames renames the canonical names in header to the names in RawHeaders.
+func (p *Parsed) rawHeaderNames(header http.Header) http.Header {
+	if !p.rawHeadersConsistent() {
+		return header
+	}
+	names := map[string]string{}
+	for _, f := range p.RawHeaders {
+		k := http.CanonicalHeaderKey(f.Name)
+		if _, ok := names[k]; !ok {
+			names[k] = f.Name
+		}
+	}
+	renamed := make(http.Header, len(header))
+	for k, v := range header {
+		if name, ok := names[k]; ok {
+			k = name
+		}
+		renamed[k] = v
+	}
+	return renamed
+}
+
+// addHeaderField adds the header to out, recording it in RawHeaders when WithRawHeaders is used.
+func (s *parseState) addHeaderField(out *Parsed, k, v string) {
+	out.Header.Add(k, v)
+	if s.rawHeaders {
+		out.RawHeaders = append(out.RawHeaders, HeaderField{Name: k, Value: v})
+	}
+}
+
+// setDefaultHeader adds the header to out unless it has already been specified.
+func (s *parseState) setDefaultHeader(out *Parsed, k, v string) {
+	if _, ok := out.Header[http.CanonicalHeaderKey(k)]; !ok {
+		s.addHeaderField(out, k, v)
+	}
+}
+
+// removeHeader removes the header from out.
+func (s *parseState) removeHeader(out *Parsed, k string) {
+	out.Header.Del(k)
+	out.RawHeaders = slices.DeleteFunc(out.RawHeaders, func(f HeaderField) bool {
+		return strings.EqualFold(f.Name, k)
+	})
+}
@@ -0,0 +1,128 @@
+package curlreq_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/k1LoW/curlreq"
+)
+
+func TestWithRawHeaders(t *testing.T) {
+	t.Parallel()
+
+	p, err := curlreq.NewParser(curlreq.WithRawHeaders(), curlreq.WithImplicitHeaders(curlreq.ImplicitAccept))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := `curl -H 'x-signature: abc' -A sloth -H 'X-Date: 2024' -H 'x-empty;' -H 'x-signature: def' -b k=v --json '{}' -H 'content-type:' https://api.example.com`
+	got, err := p.Parse(cmd)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	t.Run("order and case", func(t *testing.T) {
+		t.Parallel()
+
+		want := []curlreq.HeaderField{
+			{Name: "x-signature", Value: "abc"},
+			{Name: "User-Agent", Value: "sloth"},
+			{Name: "X-Date", Value: "2024"},
+			{Name: "x-empty", Value: ""},
+			{Name: "x-signature", Value: "def"},
+			{Name: "Cookie", Value: "k=v"},
+			{Name: "Accept", Value: "application/json"},
+		}
+		if diff := cmp.Diff(want, got.RawHeaders); diff != "" {
+			t.Errorf("unexpected raw headers (-want +got):\n%s", diff)
+		}
+		wantHeader := http.Header{
+			"X-Signature": []string{"abc", "def"},
+			"User-Agent":  []string{"sloth"},
+			"X-Date":      []string{"2024"},
+			"X-Empty":     []string{""},
+			"Cookie":      []string{"k=v"},
+			"Accept":      []string{"application/json"},
+		}
+		if diff := cmp.Diff(wantHeader, got.Header); diff != "" {
+			t.Errorf("unexpected header (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("command", func(t *testing.T) {
+		t.Parallel()
+
+		want := `curl -H 'x-signature: abc' -H 'User-Agent: sloth' -H 'X-Date: 2024' -H 'x-empty;' -H 'x-signature: def' -H 'Cookie: k=v' -H 'Accept: application/json' -H Content-Type: --data-binary '{}' https://api.example.com`
+		if got.Command() != want {
+			t.Errorf("got %s\nwant %s", got.Command(), want)
+		}
+		again, err := p.Parse(got.Command())
+		if err != nil {
+			t.Fatalf("Parse returned error: %v", err)
+		}
+		if diff := cmp.Diff(got.RawHeaders, again.RawHeaders); diff != "" {
+			t.Errorf("round trip failed (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := json.Marshal(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `"raw_headers":[{"name":"x-signature","value":"abc"},{"name":"User-Agent","value":"sloth"},`
+		if !strings.Contains(string(b), want) {
+			t.Errorf("got %s, want to contain %s", b, want)
+		}
+	})
+
+	t.Run("request", func(t *testing.T) {
+		t.Parallel()
+
+		req, err := got.Request()
+		if err != nil {
+			t.Fatalf("Request returned error: %v", err)
+		}
+		if v := req.Header.Values("X-Signature"); len(v) != 2 {
+			t.Errorf("got %v, want 2 values", v)
+		}
+		raw, err := got.Request(curlreq.WithRawHeaderNames())
+		if err != nil {
+			t.Fatalf("Request returned error: %v", err)
+		}
+		if diff := cmp.Diff([]string{"abc", "def"}, raw.Header["x-signature"]); diff != "" {
+			t.Errorf("unexpected header (-want +got):\n%s", diff)
+		}
+		if _, ok := raw.Header["X-Signature"]; ok {
+			t.Error("canonical name should not be used")
+		}
+		if _, ok := raw.Header["User-Agent"]; !ok {
+			t.Error("User-Agent should be kept")
+		}
+	})
+
+	t.Run("modified header", func(t *testing.T) {
+		t.Parallel()
+
+		modified, err := p.Parse(`curl -H 'x-b: 1' -H 'x-a: 2' https://api.example.com`)
+		if err != nil {
+			t.Fatalf("Parse returned error: %v", err)
+		}
+		modified.Header.Set("X-C", "3")
+		want := `curl -H 'Accept: */*' -H 'X-A: 2' -H 'X-B: 1' -H 'X-C: 3' https://api.example.com`
+		if modified.Command() != want {
+			t.Errorf("got %s\nwant %s", modified.Command(), want)
+		}
+		b, err := json.Marshal(modified)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), "raw_headers") {
+			t.Errorf("raw_headers should be omitted: %s", b)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package curlreq
+
+import "strconv"
+
+// ImplicitHeader is a set of headers curl sends without being specified in the command.
+type ImplicitHeader int
+
+const (
+	// ImplicitUserAgent is "User-Agent: curl/<version>".
+	ImplicitUserAgent ImplicitHeader = 1 << iota
+	// ImplicitAccept is "Accept: */*".
+	ImplicitAccept
+	// ImplicitContentType is "Content-Type: application/x-www-form-urlencoded" for -d/--data.
+	ImplicitContentType
+	// ImplicitContentLength is "Content-Length" of the body.
+	ImplicitContentLength
+	// ImplicitExpect is "Expect: 100-continue" for bodies larger than 1 MiB.
+	ImplicitExpect
+
+	// AllImplicitHeaders is all the headers curl sends implicitly.
+	AllImplicitHeaders = ImplicitUserAgent | ImplicitAccept | ImplicitContentType | ImplicitContentLength | ImplicitExpect
+)
+
+const (
+	// implicitUserAgent is the User-Agent curl sends by default.
+	implicitUserAgent = "curl/8.11.1"
+	// expectThreshold is the body size from which curl sends "Expect: 100-continue".
+	expectThreshold = 1024 * 1024
+)
+
+// WithImplicitHeaders sets the headers curl sends implicitly that are added to Parsed.Header.
+// The default is ImplicitContentType, and WithImplicitHeaders(0) adds no implicit header.
+// The headers are not added when they are specified by the command, and -H "Name:" suppresses them.
+func WithImplicitHeaders(headers ImplicitHeader) Option {
+	return func(c *config) error {
+		c.implicitHeaders = headers
+		return nil
+	}
+}
+
+// addImplicitHeaders adds the enabled implicit headers that are not specified by the command.
+func (s *parseState) addImplicitHeaders(out *Parsed) {
+	h := s.implicitHeaders
+	setDefault := func(k, v string) {
+		s.setDefaultHeader(out, k, v)
+	}
+	if h&ImplicitUserAgent != 0 {
+		setDefault("User-Agent", implicitUserAgent)
+	}
+	if h&ImplicitAccept != 0 {
+		setDefault("Accept", "*/*")
+	}
+
+	size := int64(len(out.Body))
+	if out.BodyFile != nil {
+		size = out.BodyFile.Size
+	}
+	hasBody := len(out.Body) > 0 || out.BodyFile != nil
+	if h&ImplicitContentType != 0 && hasBody && s.formData && !s.jsonData {
+		setDefault("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if h&ImplicitContentLength != 0 && hasBody {
+		setDefault("Content-Length", strconv.FormatInt(size, 10))
+	}
+	if h&ImplicitExpect != 0 && size > expectThreshold {
+		setDefault("Expect", "100-continue")
+	}
+}
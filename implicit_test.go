@@ -0,0 +1,125 @@
+package curlreq_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/k1LoW/curlreq"
+)
+
+func TestWithImplicitHeaders(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	large := strings.Repeat("a", 1024*1024+1)
+	if err := os.WriteFile(filepath.Join(dir, "large.txt"), []byte(large), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		headers curlreq.ImplicitHeader
+		input   string
+		want    http.Header
+	}{
+		{
+			name:    "GET",
+			headers: curlreq.AllImplicitHeaders,
+			input:   `curl https://api.example.com`,
+			want:    http.Header{"User-Agent": []string{"curl/8.11.1"}, "Accept": []string{"*/*"}},
+		},
+		{
+			name:    "form data",
+			headers: curlreq.AllImplicitHeaders,
+			input:   `curl -d a=b -A sloth https://api.example.com`,
+			want: http.Header{
+				"User-Agent":     []string{"sloth"},
+				"Accept":         []string{"*/*"},
+				"Content-Type":   []string{"application/x-www-form-urlencoded"},
+				"Content-Length": []string{"3"},
+			},
+		},
+		{
+			name:    "Content-Type specified",
+			headers: curlreq.ImplicitContentType,
+			input:   `curl -d a=b -H 'Content-Type: text/plain' https://api.example.com`,
+			want:    http.Header{"Content-Type": []string{"text/plain"}},
+		},
+		{
+			name:    "json",
+			headers: curlreq.ImplicitContentType | curlreq.ImplicitAccept,
+			input:   `curl --json '{}' https://api.example.com`,
+			want:    http.Header{"Content-Type": []string{"application/json"}, "Accept": []string{"application/json"}},
+		},
+		{
+			name:    "upload",
+			headers: curlreq.ImplicitContentType | curlreq.ImplicitContentLength,
+			input:   `curl -d a=b -T large.txt https://api.example.com`,
+			want:    http.Header{"Content-Length": []string{fmt.Sprint(len(large))}},
+		},
+		{
+			name:    "large body",
+			headers: curlreq.ImplicitExpect,
+			input:   `curl --data-binary @large.txt https://api.example.com`,
+			want:    http.Header{"Expect": []string{"100-continue"}},
+		},
+		{
+			name:    "-G",
+			headers: curlreq.ImplicitContentType | curlreq.ImplicitContentLength,
+			input:   `curl -G -d a=b https://api.example.com`,
+			want:    http.Header{},
+		},
+		{
+			name:    "suppressed by -H",
+			headers: curlreq.AllImplicitHeaders,
+			input:   `curl -H 'User-Agent:' -H 'Accept:' -H 'Content-Type:' -H 'Expect:' -H 'Content-Length:' --data-binary @large.txt https://api.example.com`,
+			want:    http.Header{},
+		},
+		{
+			name:    "empty header is specified",
+			headers: curlreq.ImplicitAccept,
+			input:   `curl -H 'Accept;' https://api.example.com`,
+			want:    http.Header{"Accept": []string{""}},
+		},
+		{
+			name:  "disabled",
+			input: `curl -d a=b https://api.example.com`,
+			want:  http.Header{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := curlreq.NewParser(curlreq.WithWorkingDirectory(dir), curlreq.WithImplicitHeaders(tt.headers))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got.Header); diff != "" {
+				t.Errorf("unexpected header (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseDoesNotDuplicateContentType(t *testing.T) {
+	t.Parallel()
+
+	got, err := curlreq.Parse(`curl -H 'Content-Type: application/xml' -d '<a/>' https://api.example.com`)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	want := http.Header{"Content-Type": []string{"application/xml"}}
+	if diff := cmp.Diff(want, got.Header); diff != "" {
+		t.Errorf("unexpected header (-want +got):\n%s", diff)
+	}
+}
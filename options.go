@@ -0,0 +1,363 @@
+package curlreq
+
+import "strings"
+
+// optionArity is the kind of argument a curl option takes.
+type optionArity int
+
+const (
+	// argNone is a boolean option that takes no argument.
+	argNone optionArity = iota
+	// argSingle is an option that takes an argument. The last one wins when specified multiple times.
+	argSingle
+	// argRepeat is an option that takes an argument and can be specified multiple times.
+	argRepeat
+)
+
+// optionSpec is the specification of a curl option.
+type optionSpec struct {
+	long  string
+	short byte
+	arity optionArity
+}
+
+// curlOptions is the table of curl command line options.
+var curlOptions = []optionSpec{
+	{"abstract-unix-socket", 0, argSingle},
+	{"alpn", 0, argNone},
+	{"alt-svc", 0, argSingle},
+	{"anyauth", 0, argNone},
+	{"append", 'a', argNone},
+	{"aws-sigv4", 0, argSingle},
+	{"basic", 0, argNone},
+	// -N is --no-buffer, but it is not distinguished because the option does not affect the request
+	{"buffer", 'N', argNone},
+	{"ca-native", 0, argNone},
+	{"cacert", 0, argSingle},
+	{"capath", 0, argSingle},
+	{"cert", 'E', argSingle},
+	{"cert-status", 0, argNone},
+	{"cert-type", 0, argSingle},
+	{"ciphers", 0, argSingle},
+	{"clobber", 0, argNone},
+	{"compressed", 0, argNone},
+	{"compressed-ssh", 0, argNone},
+	{"config", 'K', argRepeat},
+	{"connect-timeout", 0, argSingle},
+	{"connect-to", 0, argRepeat},
+	{"continue-at", 'C', argSingle},
+	{"cookie", 'b', argRepeat},
+	{"cookie-jar", 'c', argSingle},
+	{"create-dirs", 0, argNone},
+	{"create-file-mode", 0, argSingle},
+	{"crlf", 0, argNone},
+	{"crlfile", 0, argSingle},
+	{"curves", 0, argSingle},
+	{"data", 'd', argRepeat},
+	{"data-ascii", 0, argRepeat},
+	{"data-binary", 0, argRepeat},
+	{"data-raw", 0, argRepeat},
+	{"data-urlencode", 0, argRepeat},
+	{"delegation", 0, argSingle},
+	{"digest", 0, argNone},
+	{"disable", 'q', argNone},
+	{"disable-eprt", 0, argNone},
+	{"disable-epsv", 0, argNone},
+	{"disallow-username-in-url", 0, argNone},
+	{"dns-interface", 0, argSingle},
+	{"dns-ipv4-addr", 0, argSingle},
+	{"dns-ipv6-addr", 0, argSingle},
+	{"dns-servers", 0, argSingle},
+	{"doh-cert-status", 0, argNone},
+	{"doh-insecure", 0, argNone},
+	{"doh-url", 0, argSingle},
+	{"dump-ca-embed", 0, argNone},
+	{"dump-header", 'D', argSingle},
+	{"ech", 0, argSingle},
+	{"egd-file", 0, argSingle},
+	{"engine", 0, argSingle},
+	{"etag-compare", 0, argSingle},
+	{"etag-save", 0, argSingle},
+	{"expect100-timeout", 0, argSingle},
+	{"fail", 'f', argNone},
+	{"fail-early", 0, argNone},
+	{"fail-with-body", 0, argNone},
+	{"false-start", 0, argNone},
+	{"follow", 0, argNone},
+	{"form", 'F', argRepeat},
+	{"form-escape", 0, argNone},
+	{"form-string", 0, argRepeat},
+	{"ftp-account", 0, argSingle},
+	{"ftp-alternative-to-user", 0, argSingle},
+	{"ftp-create-dirs", 0, argNone},
+	{"ftp-method", 0, argSingle},
+	{"ftp-pasv", 0, argNone},
+	{"ftp-port", 'P', argSingle},
+	{"ftp-pret", 0, argNone},
+	{"ftp-skip-pasv-ip", 0, argNone},
+	{"ftp-ssl", 0, argNone},
+	{"ftp-ssl-ccc", 0, argNone},
+	{"ftp-ssl-ccc-mode", 0, argSingle},
+	{"ftp-ssl-control", 0, argNone},
+	{"ftp-ssl-reqd", 0, argNone},
+	{"get", 'G', argNone},
+	{"globoff", 'g', argNone},
+	{"happy-eyeballs-timeout-ms", 0, argSingle},
+	{"haproxy-clientip", 0, argSingle},
+	{"haproxy-protocol", 0, argNone},
+	{"head", 'I', argNone},
+	{"header", 'H', argRepeat},
+	{"help", 'h', argNone},
+	{"hostpubmd5", 0, argSingle},
+	{"hostpubsha256", 0, argSingle},
+	{"hsts", 0, argSingle},
+	{"http0.9", 0, argNone},
+	{"http1.0", '0', argNone},
+	{"http1.1", 0, argNone},
+	{"http2", 0, argNone},
+	{"http2-prior-knowledge", 0, argNone},
+	{"http3", 0, argNone},
+	{"http3-only", 0, argNone},
+	{"ignore-content-length", 0, argNone},
+	{"include", 'i', argNone},
+	{"insecure", 'k', argNone},
+	{"interface", 0, argSingle},
+	{"ip-tos", 0, argSingle},
+	{"ipfs-gateway", 0, argSingle},
+	{"ipv4", '4', argNone},
+	{"ipv6", '6', argNone},
+	{"json", 0, argRepeat},
+	{"junk-session-cookies", 'j', argNone},
+	{"keepalive", 0, argNone},
+	{"keepalive-cnt", 0, argSingle},
+	{"keepalive-time", 0, argSingle},
+	{"key", 0, argSingle},
+	{"key-type", 0, argSingle},
+	{"knownhosts", 0, argSingle},
+	{"krb", 0, argSingle},
+	{"libcurl", 0, argSingle},
+	{"limit-rate", 0, argSingle},
+	{"list-only", 'l', argNone},
+	{"local-port", 0, argSingle},
+	{"location", 'L', argNone},
+	{"location-trusted", 0, argNone},
+	{"login-options", 0, argSingle},
+	{"mail-auth", 0, argSingle},
+	{"mail-from", 0, argSingle},
+	{"mail-rcpt", 0, argRepeat},
+	{"mail-rcpt-allowfails", 0, argNone},
+	{"manual", 'M', argNone},
+	{"max-filesize", 0, argSingle},
+	{"max-redirs", 0, argSingle},
+	{"max-time", 'm', argSingle},
+	{"metalink", 0, argNone},
+	{"mptcp", 0, argNone},
+	{"negotiate", 0, argNone},
+	{"netrc", 'n', argNone},
+	{"netrc-file", 0, argSingle},
+	{"netrc-optional", 0, argNone},
+	{"next", ':', argNone},
+	{"noproxy", 0, argSingle},
+	{"npn", 0, argNone},
+	{"ntlm", 0, argNone},
+	{"ntlm-wb", 0, argNone},
+	{"oauth2-bearer", 0, argSingle},
+	{"out-null", 0, argNone},
+	{"output", 'o', argRepeat},
+	{"output-dir", 0, argSingle},
+	{"parallel", 'Z', argNone},
+	{"parallel-immediate", 0, argNone},
+	{"parallel-max", 0, argSingle},
+	{"parallel-max-host", 0, argSingle},
+	{"pass", 0, argSingle},
+	{"path-as-is", 0, argNone},
+	{"pinnedpubkey", 0, argSingle},
+	{"post301", 0, argNone},
+	{"post302", 0, argNone},
+	{"post303", 0, argNone},
+	{"preproxy", 0, argSingle},
+	{"progress-bar", '#', argNone},
+	{"progress-meter", 0, argNone},
+	{"proto", 0, argSingle},
+	{"proto-default", 0, argSingle},
+	{"proto-redir", 0, argSingle},
+	{"proxy", 'x', argSingle},
+	{"proxy-anyauth", 0, argNone},
+	{"proxy-basic", 0, argNone},
+	{"proxy-ca-native", 0, argNone},
+	{"proxy-cacert", 0, argSingle},
+	{"proxy-capath", 0, argSingle},
+	{"proxy-cert", 0, argSingle},
+	{"proxy-cert-type", 0, argSingle},
+	{"proxy-ciphers", 0, argSingle},
+	{"proxy-crlfile", 0, argSingle},
+	{"proxy-digest", 0, argNone},
+	{"proxy-header", 0, argRepeat},
+	{"proxy-http2", 0, argNone},
+	{"proxy-insecure", 0, argNone},
+	{"proxy-key", 0, argSingle},
+	{"proxy-key-type", 0, argSingle},
+	{"proxy-negotiate", 0, argNone},
+	{"proxy-ntlm", 0, argNone},
+	{"proxy-pass", 0, argSingle},
+	{"proxy-pinnedpubkey", 0, argSingle},
+	{"proxy-service-name", 0, argSingle},
+	{"proxy-ssl-allow-beast", 0, argNone},
+	{"proxy-ssl-auto-client-cert", 0, argNone},
+	{"proxy-tls13-ciphers", 0, argSingle},
+	{"proxy-tlsauthtype", 0, argSingle},
+	{"proxy-tlspassword", 0, argSingle},
+	{"proxy-tlsuser", 0, argSingle},
+	{"proxy-tlsv1", 0, argNone},
+	{"proxy-user", 'U', argSingle},
+	{"proxy1.0", 0, argSingle},
+	{"proxytunnel", 'p', argNone},
+	{"pubkey", 0, argSingle},
+	{"quote", 'Q', argRepeat},
+	{"random-file", 0, argSingle},
+	{"range", 'r', argSingle},
+	{"rate", 0, argSingle},
+	{"raw", 0, argNone},
+	{"referer", 'e', argSingle},
+	{"remote-header-name", 'J', argNone},
+	{"remote-name", 'O', argNone},
+	{"remote-name-all", 0, argNone},
+	{"remote-time", 'R', argNone},
+	{"remove-on-error", 0, argNone},
+	{"request", 'X', argSingle},
+	{"request-target", 0, argSingle},
+	{"resolve", 0, argRepeat},
+	{"retry", 0, argSingle},
+	{"retry-all-errors", 0, argNone},
+	{"retry-connrefused", 0, argNone},
+	{"retry-delay", 0, argSingle},
+	{"retry-max-time", 0, argSingle},
+	{"sasl-authzid", 0, argSingle},
+	{"sasl-ir", 0, argNone},
+	{"service-name", 0, argSingle},
+	{"sessionid", 0, argNone},
+	{"show-error", 'S', argNone},
+	{"show-headers", 0, argNone},
+	{"sigalgs", 0, argSingle},
+	{"silent", 's', argNone},
+	{"skip-existing", 0, argNone},
+	{"socks4", 0, argSingle},
+	{"socks4a", 0, argSingle},
+	{"socks5", 0, argSingle},
+	{"socks5-basic", 0, argNone},
+	{"socks5-gssapi", 0, argNone},
+	{"socks5-gssapi-nec", 0, argNone},
+	{"socks5-gssapi-service", 0, argSingle},
+	{"socks5-hostname", 0, argSingle},
+	{"speed-limit", 'Y', argSingle},
+	{"speed-time", 'y', argSingle},
+	{"ssl", 0, argNone},
+	{"ssl-allow-beast", 0, argNone},
+	{"ssl-auto-client-cert", 0, argNone},
+	{"ssl-no-revoke", 0, argNone},
+	{"ssl-reqd", 0, argNone},
+	{"ssl-revoke-best-effort", 0, argNone},
+	{"ssl-sessions", 0, argSingle},
+	{"sslv2", '2', argNone},
+	{"sslv3", '3', argNone},
+	{"stderr", 0, argSingle},
+	{"styled-output", 0, argNone},
+	{"suppress-connect-headers", 0, argNone},
+	{"tcp-fastopen", 0, argNone},
+	{"tcp-nodelay", 0, argNone},
+	{"telnet-option", 't', argRepeat},
+	{"tftp-blksize", 0, argSingle},
+	{"tftp-no-options", 0, argNone},
+	{"time-cond", 'z', argSingle},
+	{"tls-earlydata", 0, argNone},
+	{"tls-max", 0, argSingle},
+	{"tls13-ciphers", 0, argSingle},
+	{"tlsauthtype", 0, argSingle},
+	{"tlspassword", 0, argSingle},
+	{"tlsuser", 0, argSingle},
+	{"tlsv1", '1', argNone},
+	{"tlsv1.0", 0, argNone},
+	{"tlsv1.1", 0, argNone},
+	{"tlsv1.2", 0, argNone},
+	{"tlsv1.3", 0, argNone},
+	{"tr-encoding", 0, argNone},
+	{"trace", 0, argSingle},
+	{"trace-ascii", 0, argSingle},
+	{"trace-config", 0, argSingle},
+	{"trace-ids", 0, argNone},
+	{"trace-time", 0, argNone},
+	{"unix-socket", 0, argSingle},
+	{"upload-file", 'T', argRepeat},
+	{"upload-flags", 0, argSingle},
+	{"url", 0, argRepeat},
+	{"url-query", 0, argRepeat},
+	{"use-ascii", 'B', argNone},
+	{"user", 'u', argSingle},
+	{"user-agent", 'A', argSingle},
+	{"variable", 0, argRepeat},
+	{"verbose", 'v', argNone},
+	{"version", 'V', argNone},
+	{"vlan-priority", 0, argSingle},
+	{"write-out", 'w', argSingle},
+	{"xattr", 0, argNone},
+}
+
+// unsupportedOptions is the set of options in curlOptions that change the request but are not supported.
+// They are recorded in Parsed.Unknown, or rejected in strict mode.
+var unsupportedOptions = map[string]bool{
+	"anyauth":        true,
+	"aws-sigv4":      true,
+	"continue-at":    true,
+	"digest":         true,
+	"etag-compare":   true,
+	"form-escape":    true,
+	"negotiate":      true,
+	"netrc":          true,
+	"netrc-file":     true,
+	"netrc-optional": true,
+	"ntlm":           true,
+	"ntlm-wb":        true,
+	"oauth2-bearer":  true,
+	"proxy-header":   true,
+	"proxy-user":     true,
+	"range":          true,
+	"referer":        true,
+	"request-target": true,
+	"time-cond":      true,
+	"tr-encoding":    true,
+}
+
+var longOptions, shortOptions = indexOptions(curlOptions)
+
+func indexOptions(specs []optionSpec) (map[string]*optionSpec, map[byte]*optionSpec) {
+	long := make(map[string]*optionSpec, len(specs))
+	short := map[byte]*optionSpec{}
+	for i := range specs {
+		s := &specs[i]
+		long[s.long] = s
+		if s.short != 0 {
+			short[s.short] = s
+		}
+	}
+	return long, short
+}
+
+// lookupOption returns the specification of the option a.
+// negated is true when a boolean option is turned off with the --no- prefix.
+func lookupOption(a string) (spec *optionSpec, negated bool, ok bool) {
+	switch {
+	case strings.HasPrefix(a, "--"):
+		name := a[2:]
+		if s, ok := longOptions[name]; ok {
+			return s, false, true
+		}
+		if s, ok := longOptions[strings.TrimPrefix(name, "no-")]; ok && strings.HasPrefix(name, "no-") && s.arity == argNone {
+			return s, true, true
+		}
+	case len(a) == 2 && a[0] == '-':
+		if s, ok := shortOptions[a[1]]; ok {
+			return s, false, true
+		}
+	}
+	return nil, false, false
+}
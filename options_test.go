@@ -0,0 +1,253 @@
+package curlreq_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/k1LoW/curlreq"
+)
+
+func TestParseWithOptionTable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  *curlreq.Parsed
+	}{
+		{
+			`curl --max-time 5 https://api.example.com`,
+			&curlreq.Parsed{
+				URL:      URL(t, "https://api.example.com"),
+				Method:   http.MethodGet,
+				Header:   http.Header{},
+				Transfer: &curlreq.Transfer{MaxRedirects: 50, MaxTime: 5 * time.Second},
+			},
+		},
+		{
+			`curl https://api.example.com -x http://proxy.example.com:8080`,
+			&curlreq.Parsed{
+				URL:      URL(t, "https://api.example.com"),
+				Method:   http.MethodGet,
+				Header:   http.Header{},
+				Transfer: &curlreq.Transfer{MaxRedirects: 50, Proxy: URL(t, "http://proxy.example.com:8080")},
+			},
+		},
+		{
+			`curl -s -L -k -o out.json -w '%{http_code}' --retry 3 --connect-to ::example.org: -e https://referer.example.com https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+				Output: "out.json",
+				Transfer: &curlreq.Transfer{
+					FollowRedirects: true,
+					MaxRedirects:    50,
+					Insecure:        true,
+				},
+				Unknown: []string{"-e", "https://referer.example.com"},
+			},
+		},
+		{
+			`curl --no-progress-meter --no-location --no-compressed -H 'X-A: 1' https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodGet,
+				Header: http.Header{"X-A": []string{"1"}},
+			},
+		},
+		{
+			`curl -I --no-head https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodHead,
+				Header: http.Header{},
+			},
+		},
+		{
+			`curl --no-max-time 5 https://api.example.com`,
+			&curlreq.Parsed{
+				URL:     URL(t, "https://api.example.com"),
+				Method:  http.MethodGet,
+				Header:  http.Header{},
+				Unknown: []string{"--no-max-time"},
+			},
+		},
+		{
+			`curl --url https://api.example.com -H 'X-A: 1'`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodGet,
+				Header: http.Header{"X-A": []string{"1"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := curlreq.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("known options are accepted in strict mode", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := curlreq.NewParser(curlreq.WithStrict())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Parse(`curl -s -S -L --max-redirs 3 --compressed --http2 https://api.example.com`); err != nil {
+			t.Errorf("Parse returned error: %v", err)
+		}
+		if _, err := p.Parse(`curl --keepalive --no-keepalive --buffer -N --sessionid --no-progress-meter --alpn --no-npn --clobber https://api.example.com`); err != nil {
+			t.Errorf("Parse returned error: %v", err)
+		}
+	})
+}
+
+func TestParseWithShortOptionClusters(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  *curlreq.Parsed
+	}{
+		{
+			`curl -sSLk https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodGet,
+				Header: http.Header{},
+				Transfer: &curlreq.Transfer{
+					FollowRedirects: true,
+					MaxRedirects:    50,
+					Insecure:        true,
+				},
+			},
+		},
+		{
+			`curl -XPOST -HAccept:application/json -uuser:pass -AUA -bk=v https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{
+					"Accept":        []string{"application/json"},
+					"Authorization": []string{"Basic dXNlcjpwYXNz"},
+					"User-Agent":    []string{"UA"},
+					"Cookie":        []string{"k=v"},
+				},
+			},
+		},
+		{
+			`curl -sXPUT -sH 'X-A: 1' -sd a=b https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPut,
+				Header: http.Header{
+					"Content-Type": []string{"application/x-www-form-urlencoded"},
+					"X-A":          []string{"1"},
+				},
+				Body: []byte("a=b"),
+			},
+		},
+		{
+			`curl -d -sSL -H -XPOST https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				Body:   []byte("-sSL"),
+			},
+		},
+		{
+			`curl -s%v https://api.example.com`,
+			&curlreq.Parsed{
+				URL:     URL(t, "https://api.example.com"),
+				Method:  http.MethodGet,
+				Header:  http.Header{},
+				Unknown: []string{"-%v"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := curlreq.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseWithLongOptionValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  *curlreq.Parsed
+	}{
+		{
+			`curl --header='X-A: 1' --request=PATCH --user=a:b --cookie=k=v --user-agent=UA --data=x=y --url=https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPatch,
+				Header: http.Header{
+					"X-A":           []string{"1"},
+					"Authorization": []string{"Basic YTpi"},
+					"Cookie":        []string{"k=v"},
+					"User-Agent":    []string{"UA"},
+					"Content-Type":  []string{"application/x-www-form-urlencoded"},
+				},
+				Body: []byte("x=y"),
+			},
+		},
+		{
+			`curl --max-time=5 --form=a=b https://api.example.com`,
+			&curlreq.Parsed{
+				URL:    URL(t, "https://api.example.com"),
+				Method: http.MethodPost,
+				Header: http.Header{},
+				Form: []*curlreq.FormPart{
+					{Name: "a", Value: []byte("b")},
+				},
+				Transfer: &curlreq.Transfer{MaxRedirects: 50, MaxTime: 5 * time.Second},
+			},
+		},
+		{
+			`curl --silent=yes --data=--header=x https://api.example.com`,
+			&curlreq.Parsed{
+				URL:     URL(t, "https://api.example.com"),
+				Method:  http.MethodPost,
+				Header:  http.Header{"Content-Type": []string{"application/x-www-form-urlencoded"}},
+				Body:    []byte("--header=x"),
+				Unknown: []string{"--silent=yes"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := curlreq.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package curlreq
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileAccessPolicy restricts the files read by the command (@file, -F, -K, -T and -H @file).
+// Stdin set by WithStdin is not restricted.
+type FileAccessPolicy struct {
+	// DenyAll denies all file access.
+	DenyAll bool
+	// RestrictToWorkingDirectory denies files outside the working directory, including symlinks that point outside it.
+	// Symlinks in the filesystem set by WithFS are checked only when it has ReadLink and Lstat methods (fs.ReadLinkFS).
+	RestrictToWorkingDirectory bool
+	// Allow is the list of glob patterns (path.Match syntax) of files allowed to be read.
+	// Relative patterns match the path relative to the working directory. Empty allows all files.
+	Allow []string
+	// MaxSize is the maximum size of a file in bytes. 0 means no limit.
+	MaxSize int64
+}
+
+// WithFileAccessPolicy restricts the files read by the command.
+func WithFileAccessPolicy(policy FileAccessPolicy) Option {
+	return func(c *config) error {
+		for _, pattern := range policy.Allow {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+			}
+		}
+		if policy.MaxSize < 0 {
+			return fmt.Errorf("max file size must not be negative: %d", policy.MaxSize)
+		}
+		c.policy = policy
+		return nil
+	}
+}
+
+// allowed reports whether the file is allowed by the allowlist.
+// rel is the slash-separated path relative to the working directory, and abs is the absolute one.
+func (p *FileAccessPolicy) allowed(rel, abs string) bool {
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.Allow {
+		name := rel
+		if strings.HasPrefix(pattern, "/") {
+			name = abs
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isOutside reports whether the relative path points outside the base directory.
+func isOutside(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || strings.HasPrefix(rel, "../")
+}
@@ -0,0 +1,219 @@
+package curlreq_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/k1LoW/curlreq"
+)
+
+func TestWithFileAccessPolicy(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	wd := filepath.Join(root, "wd")
+	if err := os.MkdirAll(filepath.Join(wd, "sub"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"secret.txt":       "secret",
+		"wd/data.json":     `{"a":1}`,
+		"wd/data.txt":      "a=b",
+		"wd/sub/large.txt": strings.Repeat("x", 11),
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, target := range map[string]string{
+		"link.txt":   filepath.Join(root, "secret.txt"),
+		"rel.txt":    filepath.Join("..", "secret.txt"),
+		"inside.txt": "data.txt",
+	} {
+		if err := os.Symlink(target, filepath.Join(wd, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secret := filepath.Join(root, "secret.txt")
+
+	tests := []struct {
+		name    string
+		policy  curlreq.FileAccessPolicy
+		cmd     string
+		wantErr error
+	}{
+		{
+			name:    "deny all",
+			policy:  curlreq.FileAccessPolicy{DenyAll: true},
+			cmd:     `curl -d @data.txt https://api.example.com`,
+			wantErr: curlreq.ErrFileAccessDenied,
+		},
+		{
+			name:    "deny all config file",
+			policy:  curlreq.FileAccessPolicy{DenyAll: true},
+			cmd:     `curl -K data.txt https://api.example.com`,
+			wantErr: curlreq.ErrFileAccessDenied,
+		},
+		{
+			name:   "restrict to working directory",
+			policy: curlreq.FileAccessPolicy{RestrictToWorkingDirectory: true},
+			cmd:    `curl -d @data.txt -H @sub/large.txt https://api.example.com`,
+		},
+		{
+			name:    "absolute path outside working directory",
+			policy:  curlreq.FileAccessPolicy{RestrictToWorkingDirectory: true},
+			cmd:     `curl -d @` + secret + ` https://api.example.com`,
+			wantErr: curlreq.ErrOutsideWorkingDirectory,
+		},
+		{
+			name:    "relative path outside working directory",
+			policy:  curlreq.FileAccessPolicy{RestrictToWorkingDirectory: true},
+			cmd:     `curl -F file=@../secret.txt https://api.example.com`,
+			wantErr: curlreq.ErrOutsideWorkingDirectory,
+		},
+		{
+			name:    "symlink outside working directory",
+			policy:  curlreq.FileAccessPolicy{RestrictToWorkingDirectory: true},
+			cmd:     `curl -T link.txt https://api.example.com`,
+			wantErr: curlreq.ErrOutsideWorkingDirectory,
+		},
+		{
+			name:   "symlink without restriction",
+			policy: curlreq.FileAccessPolicy{},
+			cmd:    `curl -T link.txt https://api.example.com`,
+		},
+		{
+			name:   "allowlist",
+			policy: curlreq.FileAccessPolicy{Allow: []string{"*.json", "sub/*"}},
+			cmd:    `curl --json @data.json -H @sub/large.txt https://api.example.com`,
+		},
+		{
+			name:    "not in allowlist",
+			policy:  curlreq.FileAccessPolicy{Allow: []string{"*.json"}},
+			cmd:     `curl -d @data.txt https://api.example.com`,
+			wantErr: curlreq.ErrFileNotAllowed,
+		},
+		{
+			name:   "absolute pattern in allowlist",
+			policy: curlreq.FileAccessPolicy{Allow: []string{filepath.ToSlash(secret)}},
+			cmd:    `curl -d @` + secret + ` https://api.example.com`,
+		},
+		{
+			name:   "max size",
+			policy: curlreq.FileAccessPolicy{MaxSize: 10},
+			cmd:    `curl -d @data.txt https://api.example.com`,
+		},
+		{
+			name:    "too large",
+			policy:  curlreq.FileAccessPolicy{MaxSize: 10},
+			cmd:     `curl --data-binary @sub/large.txt https://api.example.com`,
+			wantErr: curlreq.ErrFileTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := curlreq.NewParser(curlreq.WithWorkingDirectory(wd), curlreq.WithFileAccessPolicy(tt.policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = p.Parse(tt.cmd)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Parse returned error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+			if !errors.Is(err, curlreq.ErrFileRead) {
+				t.Errorf("got %v, want %v", err, curlreq.ErrFileRead)
+			}
+		})
+	}
+
+	t.Run("stdin is not restricted", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := curlreq.NewParser(curlreq.WithStdin(strings.NewReader("a=b")), curlreq.WithFileAccessPolicy(curlreq.FileAccessPolicy{DenyAll: true}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Parse(`curl -d @- https://api.example.com`); err != nil {
+			t.Errorf("Parse returned error: %v", err)
+		}
+	})
+
+	t.Run("filesystem", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := fstest.MapFS{
+			"wd/data.txt": {Data: []byte("a=b")},
+			"secret.txt":  {Data: []byte("secret")},
+		}
+		p, err := curlreq.NewParser(
+			curlreq.WithFS(fsys),
+			curlreq.WithWorkingDirectory("wd"),
+			curlreq.WithFileAccessPolicy(curlreq.FileAccessPolicy{RestrictToWorkingDirectory: true, Allow: []string{"*.txt"}}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Parse(`curl -d @data.txt https://api.example.com`); err != nil {
+			t.Errorf("Parse returned error: %v", err)
+		}
+		if _, err := p.Parse(`curl -d @../secret.txt https://api.example.com`); !errors.Is(err, curlreq.ErrOutsideWorkingDirectory) {
+			t.Errorf("got %v, want %v", err, curlreq.ErrOutsideWorkingDirectory)
+		}
+		if _, err := p.Parse(`curl -d @/secret.txt https://api.example.com`); !errors.Is(err, curlreq.ErrOutsideWorkingDirectory) {
+			t.Errorf("got %v, want %v", err, curlreq.ErrOutsideWorkingDirectory)
+		}
+	})
+
+	t.Run("symlinks in os.DirFS", func(t *testing.T) {
+		t.Parallel()
+
+		fsys := os.DirFS(root)
+		if _, ok := fsys.(interface {
+			ReadLink(name string) (string, error)
+		}); !ok {
+			t.Skip("os.DirFS cannot read symlinks before Go 1.25")
+		}
+		p, err := curlreq.NewParser(
+			curlreq.WithFS(fsys),
+			curlreq.WithWorkingDirectory("wd"),
+			curlreq.WithFileAccessPolicy(curlreq.FileAccessPolicy{RestrictToWorkingDirectory: true}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Parse(`curl -d @inside.txt https://api.example.com`); err != nil {
+			t.Errorf("Parse returned error: %v", err)
+		}
+		for _, cmd := range []string{
+			`curl -d @link.txt https://api.example.com`,
+			`curl -T rel.txt https://api.example.com`,
+		} {
+			if _, err := p.Parse(cmd); !errors.Is(err, curlreq.ErrOutsideWorkingDirectory) {
+				t.Errorf("%s: got %v, want %v", cmd, err, curlreq.ErrOutsideWorkingDirectory)
+			}
+		}
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := curlreq.NewParser(curlreq.WithFileAccessPolicy(curlreq.FileAccessPolicy{Allow: []string{"["}})); err == nil {
+			t.Error("NewParser should return error for invalid pattern")
+		}
+		if _, err := curlreq.NewParser(curlreq.WithFileAccessPolicy(curlreq.FileAccessPolicy{MaxSize: -1})); err == nil {
+			t.Error("NewParser should return error for negative max size")
+		}
+	})
+}
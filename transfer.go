@@ -0,0 +1,252 @@
+package curlreq
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMaxRedirects is the maximum number of redirects curl follows by default.
+const defaultMaxRedirects = 50
+
+// Transfer is the options of the command that configure how the request is sent rather than the request itself.
+type Transfer struct {
+	// FollowRedirects is whether redirects are followed (-L/--location or --location-trusted).
+	FollowRedirects bool
+	// MaxRedirects is the maximum number of redirects to follow (--max-redirs). -1 means unlimited.
+	MaxRedirects int
+	// Insecure is whether the TLS certificate of the server is not verified (-k/--insecure).
+	Insecure bool
+	// MaxTime is the maximum time of the whole transfer (-m/--max-time). 0 means no limit.
+	MaxTime time.Duration
+	// ConnectTimeout is the maximum time to connect to the server (--connect-timeout). 0 means the default.
+	ConnectTimeout time.Duration
+	// Proxy is the proxy to use (-x/--proxy). nil means the proxy of the environment variables.
+	Proxy *url.URL
+	// Resolve maps "host:port" to the addresses to connect to instead of resolving the host (--resolve).
+	// The host "*" matches any host.
+	Resolve map[string][]string
+}
+
+func (t *Transfer) MarshalJSON() ([]byte, error) {
+	var proxy string
+	if t.Proxy != nil {
+		proxy = t.Proxy.String()
+	}
+	s := struct {
+		FollowRedirects bool                `json:"follow_redirects,omitempty"`
+		MaxRedirects    int                 `json:"max_redirects"`
+		Insecure        bool                `json:"insecure,omitempty"`
+		MaxTime         float64             `json:"max_time,omitempty"`
+		ConnectTimeout  float64             `json:"connect_timeout,omitempty"`
+		Proxy           string              `json:"proxy,omitempty"`
+		Resolve         map[string][]string `json:"resolve,omitempty"`
+	}{
+		FollowRedirects: t.FollowRedirects,
+		MaxRedirects:    t.MaxRedirects,
+		Insecure:        t.Insecure,
+		MaxTime:         t.MaxTime.Seconds(),
+		ConnectTimeout:  t.ConnectTimeout.Seconds(),
+		Proxy:           proxy,
+		Resolve:         t.Resolve,
+	}
+	return json.Marshal(s)
+}
+
+// args returns the arguments of curl command that reproduce the transfer options.
+func (t *Transfer) args() []string {
+	var args []string
+	if t.FollowRedirects {
+		args = append(args, "-L")
+	}
+	if t.MaxRedirects != defaultMaxRedirects {
+		args = append(args, "--max-redirs", strconv.Itoa(t.MaxRedirects))
+	}
+	if t.Insecure {
+		args = append(args, "-k")
+	}
+	if t.MaxTime > 0 {
+		args = append(args, "-m", formatSeconds(t.MaxTime))
+	}
+	if t.ConnectTimeout > 0 {
+		args = append(args, "--connect-timeout", formatSeconds(t.ConnectTimeout))
+	}
+	if t.Proxy != nil {
+		args = append(args, "-x", t.Proxy.String())
+	}
+	for _, k := range slices.Sorted(maps.Keys(t.Resolve)) {
+		addrs := make([]string, 0, len(t.Resolve[k]))
+		for _, a := range t.Resolve[k] {
+			if strings.Contains(a, ":") {
+				// IPv6 address
+				a = "[" + a + "]"
+			}
+			addrs = append(addrs, a)
+		}
+		args = append(args, "--resolve", k+":"+strings.Join(addrs, ","))
+	}
+	return args
+}
+
+// clone returns a copy of t that does not share the proxy and the resolve map with t.
+func (t *Transfer) clone() *Transfer {
+	c := *t
+	if t.Proxy != nil {
+		u := *t.Proxy
+		c.Proxy = &u
+	}
+	if t.Resolve != nil {
+		c.Resolve = make(map[string][]string, len(t.Resolve))
+		for k, v := range t.Resolve {
+			c.Resolve[k] = slices.Clone(v)
+		}
+	}
+	return &c
+}
+
+// transfer returns the transfer options of the request being parsed, creating them with curl's defaults.
+func (s *parseState) transfer() *Transfer {
+	if s.out.Transfer == nil {
+		s.out.Transfer = &Transfer{MaxRedirects: defaultMaxRedirects}
+	}
+	return s.out.Transfer
+}
+
+// applyTransferFlag applies the boolean option of the transfer options.
+func (s *parseState) applyTransferFlag(spec *optionSpec, negated bool) {
+	if negated && s.out.Transfer == nil {
+		return
+	}
+	switch spec.long {
+	case "location", "location-trusted":
+		// --location-trusted also sends the credentials to other hosts, which is not distinguished
+		s.transfer().FollowRedirects = !negated
+	case "insecure":
+		s.transfer().Insecure = !negated
+	}
+}
+
+// applyTransferValue applies the option of the transfer options that takes a value.
+func (s *parseState) applyTransferValue(spec *optionSpec, a string) error {
+	switch spec.long {
+	case "max-redirs":
+		n, err := strconv.Atoi(a)
+		if err != nil || n < -1 {
+			return fmt.Errorf("invalid number of redirects: %q", a)
+		}
+		s.transfer().MaxRedirects = n
+	case "max-time":
+		d, err := parseSeconds(a)
+		if err != nil {
+			return err
+		}
+		s.transfer().MaxTime = d
+	case "connect-timeout":
+		d, err := parseSeconds(a)
+		if err != nil {
+			return err
+		}
+		s.transfer().ConnectTimeout = d
+	case "proxy":
+		if a == "" {
+			// An empty proxy disables the proxy of the environment variables, which is not supported
+			return nil
+		}
+		u, err := parseProxy(a)
+		if err != nil {
+			return err
+		}
+		s.transfer().Proxy = u
+	case "resolve":
+		t := s.transfer()
+		if t.Resolve == nil {
+			t.Resolve = map[string][]string{}
+		}
+		return parseResolve(t.Resolve, a)
+	}
+	return nil
+}
+
+// parseSeconds parses seconds with an optional fraction such as "2.5".
+func parseSeconds(a string) (time.Duration, error) {
+	f, err := strconv.ParseFloat(a, 64)
+	if err != nil || math.IsNaN(f) || f < 0 || math.IsInf(f, 0) || f > math.MaxInt64/float64(time.Second) {
+		return 0, fmt.Errorf("invalid seconds: %q", a)
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
+
+// formatSeconds formats d as seconds with a fraction if needed.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// parseProxy parses the proxy of -x/--proxy. As curl does, the scheme defaults to http
+// and the port defaults to 1080 (443 for https).
+func parseProxy(a string) (*url.URL, error) {
+	if !strings.Contains(a, "://") {
+		a = "http://" + a
+	}
+	u, err := url.Parse(a)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %w", err)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid proxy: %q", a)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Port() == "" {
+		port := "1080"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		u.Host = net.JoinHostPort(u.Hostname(), port)
+	}
+	return u, nil
+}
+
+// parseResolve parses the value of --resolve ([+]host:port:addr[,addr]... or -host:port) into resolve.
+func parseResolve(resolve map[string][]string, a string) error {
+	remove := strings.HasPrefix(a, "-")
+	v := strings.TrimPrefix(strings.TrimPrefix(a, "+"), "-")
+	host, rest, ok := splitResolveHost(v)
+	if !ok {
+		return fmt.Errorf("invalid resolve: %q", a)
+	}
+	port, addrs, _ := strings.Cut(rest, ":")
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("invalid resolve: %q", a)
+	}
+	key := net.JoinHostPort(strings.ToLower(host), port)
+	if remove {
+		delete(resolve, key)
+		return nil
+	}
+	var list []string
+	for addr := range strings.SplitSeq(addrs, ",") {
+		addr = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(addr), "["), "]")
+		if net.ParseIP(addr) == nil {
+			return fmt.Errorf("invalid resolve: %q", a)
+		}
+		list = append(list, addr)
+	}
+	resolve[key] = list
+	return nil
+}
+
+// splitResolveHost splits the host of --resolve, which can be an IPv6 address in brackets, from the rest.
+func splitResolveHost(v string) (string, string, bool) {
+	if strings.HasPrefix(v, "[") {
+		host, rest, ok := strings.Cut(v[1:], "]:")
+		return host, rest, ok && host != ""
+	}
+	host, rest, ok := strings.Cut(v, ":")
+	return host, rest, ok && host != ""
+}
@@ -0,0 +1,156 @@
+package curlreq_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/k1LoW/curlreq"
+)
+
+func TestParseWithTransferOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  *curlreq.Transfer
+	}{
+		{
+			`curl https://api.example.com`,
+			nil,
+		},
+		{
+			`curl -L --max-redirs 3 https://api.example.com`,
+			&curlreq.Transfer{FollowRedirects: true, MaxRedirects: 3},
+		},
+		{
+			`curl -L --no-location --max-redirs -1 https://api.example.com`,
+			&curlreq.Transfer{MaxRedirects: -1},
+		},
+		{
+			`curl --location-trusted https://api.example.com`,
+			&curlreq.Transfer{FollowRedirects: true, MaxRedirects: 50},
+		},
+		{
+			`curl --no-insecure -k -m 2.5 --connect-timeout 0.25 https://api.example.com`,
+			&curlreq.Transfer{MaxRedirects: 50, Insecure: true, MaxTime: 2500 * time.Millisecond, ConnectTimeout: 250 * time.Millisecond},
+		},
+		{
+			`curl -x proxy.example.com https://api.example.com`,
+			&curlreq.Transfer{MaxRedirects: 50, Proxy: URL(t, "http://proxy.example.com:1080")},
+		},
+		{
+			`curl --proxy HTTPS://proxy.example.com https://api.example.com`,
+			&curlreq.Transfer{MaxRedirects: 50, Proxy: URL(t, "https://proxy.example.com:443")},
+		},
+		{
+			`curl --proxy socks5h://[::1]:9050 https://api.example.com`,
+			&curlreq.Transfer{MaxRedirects: 50, Proxy: URL(t, "socks5h://[::1]:9050")},
+		},
+		{
+			`curl --resolve API.example.com:443:127.0.0.1,[::1] --resolve '*:80:10.0.0.1' --resolve +[::2]:8080:10.0.0.2 --resolve other.example.com:443:10.0.0.3 --resolve -other.example.com:443 https://api.example.com`,
+			&curlreq.Transfer{
+				MaxRedirects: 50,
+				Resolve: map[string][]string{
+					"api.example.com:443": {"127.0.0.1", "::1"},
+					"*:80":                {"10.0.0.1"},
+					"[::2]:8080":          {"10.0.0.2"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := curlreq.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got.Transfer); diff != "" {
+				t.Errorf("unexpected transfer options (-want +got):\n%s", diff)
+			}
+			assertRoundTrip(t, got)
+		})
+	}
+
+	t.Run("each URL has its own options", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := curlreq.ParseAll(`curl -L https://api.example.com/[1-2] --next -m 1 https://api.example.com/3`)
+		if err != nil {
+			t.Fatalf("ParseAll returned error: %v", err)
+		}
+		want := []*curlreq.Transfer{
+			{FollowRedirects: true, MaxRedirects: 50},
+			{FollowRedirects: true, MaxRedirects: 50},
+			{MaxRedirects: 50, MaxTime: time.Second},
+		}
+		var transfers []*curlreq.Transfer
+		for _, p := range got {
+			transfers = append(transfers, p.Transfer)
+		}
+		if diff := cmp.Diff(want, transfers); diff != "" {
+			t.Errorf("unexpected transfer options (-want +got):\n%s", diff)
+		}
+		if got[0].Transfer == got[1].Transfer {
+			t.Error("transfer options should not be shared")
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		t.Parallel()
+
+		for _, in := range []string{
+			`curl --max-redirs x https://api.example.com`,
+			`curl --max-redirs -2 https://api.example.com`,
+			`curl -m -1 https://api.example.com`,
+			`curl --connect-timeout 1s https://api.example.com`,
+			`curl --max-time nan https://api.example.com`,
+			`curl --connect-timeout NaN https://api.example.com`,
+			`curl -m inf https://api.example.com`,
+			`curl -x http:// https://api.example.com`,
+			`curl --resolve api.example.com:443 https://api.example.com`,
+			`curl --resolve api.example.com:x:127.0.0.1 https://api.example.com`,
+			`curl --resolve api.example.com:443:localhost https://api.example.com`,
+		} {
+			_, err := curlreq.Parse(in)
+			var pe *curlreq.ParseError
+			if !errors.As(err, &pe) {
+				t.Errorf("%s: got %v, want *curlreq.ParseError", in, err)
+			}
+		}
+	})
+}
+
+func TestTransferMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	p := &curlreq.Parsed{
+		URL:    URL(t, "https://api.example.com"),
+		Method: http.MethodGet,
+		Header: http.Header{},
+		Transfer: &curlreq.Transfer{
+			FollowRedirects: true,
+			MaxRedirects:    50,
+			MaxTime:         1500 * time.Millisecond,
+			Proxy:           URL(t, "http://proxy.example.com:1080"),
+			Resolve:         map[string][]string{"api.example.com:443": {"127.0.0.1"}},
+		},
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"url":"https://api.example.com","method":"GET","header":{},"transfer":{"follow_redirects":true,"max_redirects":50,"max_time":1.5,"proxy":"http://proxy.example.com:1080","resolve":{"api.example.com:443":["127.0.0.1"]}}}`
+	if diff := cmp.Diff(want, string(b)); diff != "" {
+		t.Errorf("unexpected JSON (-want +got):\n%s", diff)
+	}
+	wantCmd := `curl -L -m 1.5 -x http://proxy.example.com:1080 --resolve api.example.com:443:127.0.0.1 https://api.example.com`
+	if got := p.Command(); got != wantCmd {
+		t.Errorf("got %s\nwant %s", got, wantCmd)
+	}
+}